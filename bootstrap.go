@@ -0,0 +1,87 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+// createAdmin creates the first admin, or promotes the user that already
+// has the email. It refuses once there's an admin, from then on roles are
+// handed out through the API.
+//
+//	go run . create-admin -email admin@example.com -password secret
+func createAdmin(dbURL string, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin")
+	password := flags.String("password", "", "password, only used when the user doesn't exist yet")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := database.New(db).WithTx(tx)
+
+	// two bootstraps running at once could both see no admin
+	_, err = tx.ExecContext(ctx, "LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE")
+	if err != nil {
+		return err
+	}
+	admins, err := qtx.CountAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if admins > 0 {
+		return errors.New("there's already an admin, use the API to change roles")
+	}
+
+	user, err := qtx.GetUserByEmail(ctx, *email)
+	if errors.Is(err, sql.ErrNoRows) {
+		if *password == "" {
+			return errors.New("-password is required to create a new user")
+		}
+		hash, err := auth.HashPassword(*password)
+		if err != nil {
+			return err
+		}
+		created, err := qtx.CreateUser(ctx, database.CreateUserParams{Email: *email, HashedPassword: hash})
+		if err != nil {
+			return err
+		}
+		user.ID = created.ID
+	} else if err != nil {
+		return err
+	}
+
+	_, err = qtx.SetUserRole(ctx, database.SetUserRoleParams{ID: user.ID, Role: auth.RoleAdmin})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now an admin\n", *email)
+	return nil
+}
\ No newline at end of file
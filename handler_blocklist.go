@@ -0,0 +1,295 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/moderation"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+const (
+	blocklistChannel		= "blocklist"
+	blocklistChangeAdded	= "added"
+	blocklistChangeUpdated	= "updated"
+	blocklistChangeRemoved	= "removed"
+)
+
+type BlocklistTerm struct {
+	Word		string			`json:"word"`
+	Action		string			`json:"action"`
+	CreatedAt	time.Time		`json:"created_at"`
+	AddedBy		uuid.NullUUID	`json:"added_by"`
+}
+
+type BlocklistChange struct {
+	ID			uuid.UUID		`json:"id"`
+	CreatedAt	time.Time		`json:"created_at"`
+	Word		string			`json:"word"`
+	Change		string			`json:"change"`
+	Action		string			`json:"action,omitempty"`
+	UserID		uuid.NullUUID	`json:"user_id"`
+}
+
+func mapBlocklistTerm(w database.ProfaneWord) BlocklistTerm {
+	return BlocklistTerm{
+		Word:		w.Word,
+		Action:		w.Action,
+		CreatedAt:	w.CreatedAt,
+		AddedBy:	w.AddedBy,
+	}
+}
+
+// handlerGetBlocklist lists the terms managed through the API, the ones
+// from the word list file aren't included.
+func (cfg *apiConfig) handlerGetBlocklist(rw http.ResponseWriter, req *http.Request) {
+	words, err := cfg.db.ListProfaneWords(req.Context())
+	if err != nil {
+		log.Printf("Error retrieving the blocklist from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve blocklist")
+		return
+	}
+
+	terms := []BlocklistTerm{}
+	for _, w := range words {
+		terms = append(terms, mapBlocklistTerm(w))
+	}
+	respondWithJSON(rw, 200, terms)
+}
+
+// handlerAddBlocklistTerm adds a term or changes the action of one that's
+// already listed.
+func (cfg *apiConfig) handlerAddBlocklistTerm(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Word	string	`json:"word"`
+		Action	string	`json:"action"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	if params.Action == "" {
+		params.Action = string(moderation.ActionMask)
+	}
+	_, err = moderation.NewFilter([]moderation.Rule{{Word: params.Word, Action: moderation.Action(params.Action)}})
+	if err != nil {
+		respondWithError(rw, 400, "Invalid term, must be a single word with an action of mask, flag or reject")
+		return
+	}
+	word := moderation.Normalize(params.Word)
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	status := 201
+	change := blocklistChangeAdded
+	added, err := qtx.AddProfaneWord(req.Context(), database.AddProfaneWordParams{
+		Word:		word,
+		Action:		params.Action,
+		AddedBy:	uuid.NullUUID{UUID: userId, Valid: true},
+	})
+	if err == nil && added == 0 {
+		status = 200
+		change = blocklistChangeUpdated
+		added, err = qtx.UpdateProfaneWord(req.Context(), database.UpdateProfaneWordParams{Word: word, Action: params.Action})
+	}
+	if err != nil {
+		log.Printf("Error storing the term on the database: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+
+	// setting the action a term already has changes nothing worth recording
+	if added > 0 {
+		err = recordBlocklistChange(req.Context(), qtx, userId, word, change, params.Action)
+		if err != nil {
+			log.Printf("Error recording the blocklist change: %s", err)
+			respondWithError(rw, 500, "Can't update blocklist")
+			return
+		}
+	}
+
+	term, err := qtx.GetProfaneWord(req.Context(), word)
+	if err != nil {
+		log.Printf("Error retrieving the term from the database: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the blocklist change: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+	cfg.refreshProfanityFilter(req.Context())
+	respondWithJSON(rw, status, mapBlocklistTerm(term))
+}
+
+func (cfg *apiConfig) handlerDeleteBlocklistTerm(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	word := moderation.Normalize(req.PathValue("word"))
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	deleted, err := qtx.DeleteProfaneWord(req.Context(), word)
+	if err != nil {
+		log.Printf("Error deleting the term from the database: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(rw, 404, "Term not found")
+		return
+	}
+
+	err = recordBlocklistChange(req.Context(), qtx, userId, word, blocklistChangeRemoved, "")
+	if err != nil {
+		log.Printf("Error recording the blocklist change: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the blocklist change: %s", err)
+		respondWithError(rw, 500, "Can't update blocklist")
+		return
+	}
+	cfg.refreshProfanityFilter(req.Context())
+	rw.WriteHeader(204)
+}
+
+// handlerGetBlocklistChanges lists who changed the blocklist and how, most
+// recent first, optionally for a single term.
+func (cfg *apiConfig) handlerGetBlocklistChanges(rw http.ResponseWriter, req *http.Request) {
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	word := sql.NullString{}
+	if w := req.URL.Query().Get("word"); w != "" {
+		word = sql.NullString{String: moderation.Normalize(w), Valid: true}
+	}
+
+	var changes []database.ProfaneWordChange
+	if page.backwards() {
+		changes, err = cfg.db.ListProfaneWordChangesAfter(req.Context(), database.ListProfaneWordChangesAfterParams{
+			Word:			word,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		changes, err = cfg.db.ListProfaneWordChangesBefore(req.Context(), database.ListProfaneWordChangesBeforeParams{
+			Word:				word,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the blocklist changes from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve blocklist changes")
+		return
+	}
+
+	changes, next, prev := paginate(changes, page, func(c database.ProfaneWordChange) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedChanges := []BlocklistChange{}
+	for _, c := range changes {
+		mappedChanges = append(mappedChanges, BlocklistChange{
+			ID:			c.ID,
+			CreatedAt:	c.CreatedAt,
+			Word:		c.Word,
+			Change:		c.Change,
+			Action:		c.Action.String,
+			UserID:		c.UserID,
+		})
+	}
+	respondWithJSON(rw, 200, mappedChanges)
+}
+
+// recordBlocklistChange keeps the audit trail of the change and, once the
+// transaction commits, tells every server to reload its filter.
+func recordBlocklistChange(ctx context.Context, db *database.Queries, userID uuid.UUID, word, change, action string) error {
+	err := db.CreateProfaneWordChange(ctx, database.CreateProfaneWordChangeParams{
+		Word:	word,
+		Change:	change,
+		Action:	sql.NullString{String: action, Valid: action != ""},
+		UserID:	uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	return db.Notify(ctx, database.NotifyParams{Channel: blocklistChannel, Payload: word})
+}
+
+// refreshProfanityFilter reloads the filter right away, so the change
+// applies to this server's next request without waiting for the
+// notification to come back.
+func (cfg *apiConfig) refreshProfanityFilter(ctx context.Context) {
+	err := cfg.loadProfanityFilter(ctx)
+	if err != nil {
+		log.Printf("Error reloading the profanity filter: %s", err)
+	}
+}
\ No newline at end of file
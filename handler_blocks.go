@@ -0,0 +1,273 @@
+package main
+
+import(
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+// relationsChangedEvent tells the live connections of a user that their
+// blocks or mutes changed, so they can reload who to hide. It never
+// reaches the client.
+const relationsChangedEvent = "relations.changed"
+
+type BlockedUser struct {
+	ID			uuid.UUID	`json:"id"`
+	Handle		string		`json:"handle,omitempty"`
+	BlockedAt	time.Time	`json:"blocked_at"`
+}
+
+type MutedUser struct {
+	ID			uuid.UUID	`json:"id"`
+	Handle		string		`json:"handle,omitempty"`
+	MutedAt		time.Time	`json:"muted_at"`
+}
+
+// relationTarget authenticates the caller of a block or mute endpoint and
+// resolves the user in the path, it responds on its own when either fails.
+func (cfg *apiConfig) relationTarget(rw http.ResponseWriter, req *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	targetID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if targetID == userId {
+		respondWithError(rw, 400, "Users can't block or mute themselves")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	_, err = cfg.db.GetUserByID(req.Context(), targetID)
+	if err != nil {
+		respondWithError(rw, 404, "User not found")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userId, targetID, true
+}
+
+// announceRelationsChanged sends relationsChangedEvent to the users on
+// every server instance, once db's transaction commits if there's one.
+func announceRelationsChanged(ctx context.Context, db *database.Queries, userIDs ...uuid.UUID) error {
+	for _, userID := range userIDs {
+		payload, err := json.Marshal(stream.Event{
+			Kind:		relationsChangedEvent,
+			Recipient:	uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		err = db.Notify(ctx, database.NotifyParams{Channel: notificationsChannel, Payload: string(payload)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handlerBlockUser also breaks any follow between the two users, so the
+// blocked user stops showing up in the blocker's timeline and vice versa.
+func (cfg *apiConfig) handlerBlockUser(rw http.ResponseWriter, req *http.Request) {
+	userId, targetID, ok := cfg.relationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't block user")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	_, err = qtx.BlockUser(req.Context(), database.BlockUserParams{BlockerID: userId, BlockedID: targetID})
+	if err != nil {
+		log.Printf("Error storing the block on the database: %s", err)
+		respondWithError(rw, 500, "Can't block user")
+		return
+	}
+
+	err = qtx.DeleteFollowsBetween(req.Context(), database.DeleteFollowsBetweenParams{UserID: userId, OtherID: targetID})
+	if err != nil {
+		log.Printf("Error removing the follows of a blocked user: %s", err)
+		respondWithError(rw, 500, "Can't block user")
+		return
+	}
+
+	err = announceRelationsChanged(req.Context(), qtx, userId, targetID)
+	if err != nil {
+		log.Printf("Error announcing the block to live connections: %s", err)
+		respondWithError(rw, 500, "Can't block user")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the block: %s", err)
+		respondWithError(rw, 500, "Can't block user")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(rw http.ResponseWriter, req *http.Request) {
+	userId, targetID, ok := cfg.relationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.UnblockUser(req.Context(), database.UnblockUserParams{BlockerID: userId, BlockedID: targetID})
+	if err != nil {
+		log.Printf("Error removing the block from the database: %s", err)
+		respondWithError(rw, 500, "Can't unblock user")
+		return
+	}
+
+	err = announceRelationsChanged(req.Context(), cfg.db, userId, targetID)
+	if err != nil {
+		log.Printf("Error announcing the change to live connections: %s", err)
+		respondWithError(rw, 500, "Can't unblock user")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerMuteUser(rw http.ResponseWriter, req *http.Request) {
+	userId, targetID, ok := cfg.relationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.MuteUser(req.Context(), database.MuteUserParams{MuterID: userId, MutedID: targetID})
+	if err != nil {
+		log.Printf("Error storing the mute on the database: %s", err)
+		respondWithError(rw, 500, "Can't mute user")
+		return
+	}
+
+	err = announceRelationsChanged(req.Context(), cfg.db, userId)
+	if err != nil {
+		log.Printf("Error announcing the change to live connections: %s", err)
+		respondWithError(rw, 500, "Can't mute user")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(rw http.ResponseWriter, req *http.Request) {
+	userId, targetID, ok := cfg.relationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.UnmuteUser(req.Context(), database.UnmuteUserParams{MuterID: userId, MutedID: targetID})
+	if err != nil {
+		log.Printf("Error removing the mute from the database: %s", err)
+		respondWithError(rw, 500, "Can't unmute user")
+		return
+	}
+
+	err = announceRelationsChanged(req.Context(), cfg.db, userId)
+	if err != nil {
+		log.Printf("Error announcing the change to live connections: %s", err)
+		respondWithError(rw, 500, "Can't unmute user")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
+
+// handlerGetBlocks lists the users the caller has blocked, blocks are
+// private so there's no way to look at someone else's.
+func (cfg *apiConfig) handlerGetBlocks(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	blocked, err := cfg.db.ListBlockedUsers(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving the blocks from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve blocks")
+		return
+	}
+
+	mappedUsers := []BlockedUser{}
+	for _, u := range blocked {
+		mappedUsers = append(mappedUsers, BlockedUser{ID: u.ID, Handle: u.Handle.String, BlockedAt: u.BlockedAt})
+	}
+	respondWithJSON(rw, 200, mappedUsers)
+}
+
+func (cfg *apiConfig) handlerGetMutes(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	muted, err := cfg.db.ListMutedUsers(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving the mutes from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve mutes")
+		return
+	}
+
+	mappedUsers := []MutedUser{}
+	for _, u := range muted {
+		mappedUsers = append(mappedUsers, MutedUser{ID: u.ID, Handle: u.Handle.String, MutedAt: u.MutedAt})
+	}
+	respondWithJSON(rw, 200, mappedUsers)
+}
+
+// isBlocked reports whether either user has blocked the other. Anonymous
+// viewers are never blocked.
+func (cfg *apiConfig) isBlocked(ctx context.Context, viewer uuid.NullUUID, userID uuid.UUID) (bool, error) {
+	if !viewer.Valid || viewer.UUID == userID {
+		return false, nil
+	}
+	return cfg.db.IsBlocked(ctx, database.IsBlockedParams{UserID: viewer.UUID, OtherID: userID})
+}
\ No newline at end of file
@@ -0,0 +1,164 @@
+package main
+
+import(
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+func (cfg *apiConfig) handlerBookmarkChirp(rw http.ResponseWriter, req *http.Request) {
+	cfg.setBookmark(rw, req, true)
+}
+
+func (cfg *apiConfig) handlerUnbookmarkChirp(rw http.ResponseWriter, req *http.Request) {
+	cfg.setBookmark(rw, req, false)
+}
+
+// setBookmark is idempotent both ways like setLike. Bookmarks are private,
+// nobody but the user gets to know about them.
+func (cfg *apiConfig) setBookmark(rw http.ResponseWriter, req *http.Request, bookmarked bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	// like likes, removing a bookmark works even once the chirp is gone
+	if !bookmarked {
+		_, err = cfg.db.UnbookmarkChirp(req.Context(), database.UnbookmarkChirpParams{UserID: userId, ChirpID: parsedUUID})
+		if err != nil {
+			log.Printf("Error updating the bookmark on the database: %s", err)
+			respondWithError(rw, 500, "Can't update bookmark")
+			return
+		}
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	chirp, err := cfg.db.GetVisibleChirp(req.Context(), database.GetVisibleChirpParams{ID: parsedUUID, ViewerID: viewer})
+	if err != nil && !bookmarked {
+		rw.WriteHeader(204)
+		return
+	}
+	if err != nil {
+		respondWithError(rw, 404, "Chirp not found")
+		return
+	}
+
+	if bookmarked {
+		blocked, err := cfg.isBlocked(req.Context(), viewer, chirp.UserID)
+		if err != nil {
+			log.Printf("Error checking the blocks of the author: %s", err)
+			respondWithError(rw, 500, "Can't update bookmark")
+			return
+		}
+		if blocked {
+			respondWithError(rw, 404, "Chirp not found")
+			return
+		}
+
+		_, err = cfg.db.BookmarkChirp(req.Context(), database.BookmarkChirpParams{UserID: userId, ChirpID: chirp.ID})
+		if err != nil {
+			log.Printf("Error updating the bookmark on the database: %s", err)
+			respondWithError(rw, 500, "Can't update bookmark")
+			return
+		}
+	}
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps[0])
+}
+
+// handlerGetBookmarks lists the caller's bookmarks, most recently saved first.
+func (cfg *apiConfig) handlerGetBookmarks(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	var bookmarks []database.ListBookmarksBeforeRow
+	if page.backwards() {
+		rows, err := cfg.db.ListBookmarksAfter(req.Context(), database.ListBookmarksAfterParams{
+			UserID:				userId,
+			AfterBookmarkedAt:	page.cursor.CreatedAt,
+			AfterID:			page.cursor.ID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the bookmarks from the database: %s", err)
+			respondWithError(rw, 500, "Can't retrieve bookmarks")
+			return
+		}
+		for _, r := range rows {
+			bookmarks = append(bookmarks, database.ListBookmarksBeforeRow(r))
+		}
+	} else {
+		beforeBookmarkedAt, beforeID := page.position()
+		bookmarks, err = cfg.db.ListBookmarksBefore(req.Context(), database.ListBookmarksBeforeParams{
+			UserID:				userId,
+			BeforeBookmarkedAt:	beforeBookmarkedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the bookmarks from the database: %s", err)
+			respondWithError(rw, 500, "Can't retrieve bookmarks")
+			return
+		}
+	}
+
+	bookmarks, next, prev := paginate(bookmarks, page, func(b database.ListBookmarksBeforeRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: b.BookmarkedAt, ID: b.Chirp.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	chirps := []database.Chirp{}
+	for _, b := range bookmarks {
+		chirps = append(chirps, b.Chirp)
+	}
+	mappedChirps, err := cfg.mapChirps(req.Context(), uuid.NullUUID{UUID: userId, Valid: true}, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve bookmarks")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps)
+}
\ No newline at end of file
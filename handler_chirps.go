@@ -1,20 +1,38 @@
 package main
 
 import(
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/neriAle/chirpy/internal/auth"
 	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+const (
+	chirpKindChirp		= "chirp"
+	chirpKindRechirp	= "rechirp"
+	chirpKindQuote		= "quote"
 )
 
 func (cfg *apiConfig) handlerCreateChirp(rw http.ResponseWriter, req *http.Request) {
 	type parameters struct {
-		Body   string `json:"body"`
-		UserID uuid.UUID `json:"user_id"`
+		Body   		string `json:"body"`
+		UserID 		uuid.UUID `json:"user_id"`
+		InReplyTo	uuid.NullUUID `json:"in_reply_to"`
+		Kind		string `json:"kind"`
+		OriginalID	uuid.NullUUID `json:"original_id"`
+		MediaIDs	[]uuid.UUID `json:"media_ids"`
+		Poll		*pollParams `json:"poll"`
 	}
 	params := parameters{}
 
@@ -42,39 +60,226 @@ func (cfg *apiConfig) handlerCreateChirp(rw http.ResponseWriter, req *http.Reque
 
 	params.UserID = userId
 
-	if len(params.Body) > 140 {
-		respondWithError(rw, 400, "Chirp is too long")
+	var flagged bool
+	params.Body, flagged, err = cfg.cleanChirpBody(params.Body)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+
+	_, err = cfg.checkReply(req.Context(), userId, params.InReplyTo)
+	if respondToCheck(rw, err, "Can't create chirp") {
+		return
+	}
+
+	if params.Kind == "" {
+		params.Kind = chirpKindChirp
+	}
+	switch params.Kind {
+	case chirpKindChirp:
+		if params.OriginalID.Valid {
+			respondWithError(rw, 400, "Only rechirps and quotes can reference an original chirp")
+			return
+		}
+	case chirpKindRechirp, chirpKindQuote:
+		if !params.OriginalID.Valid {
+			respondWithError(rw, 400, "Missing original chirp ID")
+			return
+		}
+		if params.Kind == chirpKindRechirp && (params.Body != "" || params.InReplyTo.Valid || len(params.MediaIDs) > 0 || params.Poll != nil) {
+			respondWithError(rw, 400, "A rechirp can't have a body, media or a poll or be a reply")
+			return
+		}
+		if params.Kind == chirpKindQuote && strings.TrimSpace(params.Body) == "" {
+			respondWithError(rw, 400, "A quote needs some commentary")
+			return
+		}
+
+		original, err := cfg.db.GetVisibleChirp(req.Context(), database.GetVisibleChirpParams{ID: params.OriginalID.UUID, ViewerID: viewer})
+		if err != nil {
+			respondWithError(rw, 400, "The original chirp doesn't exist")
+			return
+		}
+		blocked, err := cfg.isBlocked(req.Context(), viewer, original.UserID)
+		if err != nil {
+			log.Printf("Error checking the blocks of the author: %s", err)
+			respondWithError(rw, 500, "Can't create chirp")
+			return
+		}
+		if blocked {
+			respondWithError(rw, 403, "Not allowed to share chirps of this user")
+			return
+		}
+		// rechirping a rechirp shares the chirp underneath it
+		if original.Kind == chirpKindRechirp && original.OriginalID.Valid {
+			params.OriginalID = original.OriginalID
+		}
+	default:
+		respondWithError(rw, 400, "Invalid kind, must be chirp, rechirp or quote")
 		return
-	} else {
-		params.Body = replaceProfaneWords(params.Body, getProfaneWords())
 	}
 
-	chirp, err := cfg.db.CreateChirp(req.Context(), database.CreateChirpParams(params))
+	mediaIDs, err := cfg.checkMedia(req.Context(), userId, params.MediaIDs)
+	if respondToCheck(rw, err, "Can't create chirp") {
+		return
+	}
+
+	if params.Poll != nil {
+		err = cleanPoll(cfg.profanity.Load(), params.Poll)
+		if err != nil {
+			respondWithError(rw, 400, err.Error())
+			return
+		}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't create chirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, event, err := storeChirp(req.Context(), qtx, database.CreateChirpParams{
+		Body:		params.Body,
+		UserID:		params.UserID,
+		InReplyTo:	params.InReplyTo,
+		Kind:		params.Kind,
+		OriginalID:	params.OriginalID,
+	}, mediaIDs)
+	if isUniqueViolation(err, "chirps_user_id_original_id_rechirp_key") {
+		respondWithError(rw, 409, "Chirp was already rechirped")
+		return
+	}
+	if isUniqueViolation(err, "chirp_media_media_id_key") {
+		respondWithError(rw, 409, "Media is already attached to another chirp")
+		return
+	}
 	if err != nil {
 		log.Printf("Error creating the chirp on the database: %s", err)
 		respondWithError(rw, 500, "Can't create chirp")
 		return
 	}
 
-	mappedChirp := Chirp(chirp)
-	respondWithJSON(rw, 201, mappedChirp)
+	if params.Poll != nil {
+		err = qtx.CreatePoll(req.Context(), database.CreatePollParams{ChirpID: chirp.ID, ClosesAt: params.Poll.ClosesAt})
+		if err == nil {
+			err = qtx.AddPollOptions(req.Context(), database.AddPollOptionsParams{ChirpID: chirp.ID, Texts: params.Poll.Options})
+		}
+		if err != nil {
+			log.Printf("Error creating the poll on the database: %s", err)
+			respondWithError(rw, 500, "Can't create chirp")
+			return
+		}
+	}
+
+	if flagged {
+		err = flagChirp(req.Context(), qtx, chirp)
+		if err != nil {
+			log.Printf("Error flagging the chirp for review: %s", err)
+			respondWithError(rw, 500, "Can't create chirp")
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the chirp: %s", err)
+		respondWithError(rw, 500, "Can't create chirp")
+		return
+	}
+	if event != nil {
+		cfg.hub.Publish(*event)
+	}
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 201, mappedChirps[0])
 	return
 }
 
 func (cfg *apiConfig) handlerGetChirps(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	authorID := uuid.NullUUID{}
 	s := req.URL.Query().Get("author_id")
-	var chirps []database.Chirp
-	var err error
-	if s == "" {
-		chirps, err = cfg.db.ListChirps(req.Context())
-	} else {
+	if s != "" {
 		parsedUUID, err := uuid.Parse(s)
 		if err != nil {
 			log.Printf("The ID of the user can't be parsed into a UUID")
 			respondWithError(rw, 400, "Invalid user ID")
 			return
 		}
-		chirps, err = cfg.db.GetChirpsByAuthor(req.Context(), parsedUUID)
+		authorID = uuid.NullUUID{UUID: parsedUUID, Valid: true}
+	}
+
+	sort := req.URL.Query().Get("sort")
+	if sort == "" {
+		sort = "asc"
+	}
+	if sort != "asc" && sort != "desc" {
+		respondWithError(rw, 400, "Invalid sort, must be asc or desc")
+		return
+	}
+
+	// since is inclusive and until exclusive, so consecutive windows never overlap
+	since, err := parseTimeParam(req, "since")
+	if err != nil {
+		respondWithError(rw, 400, "Invalid since, must be an RFC 3339 timestamp")
+		return
+	}
+	until, err := parseTimeParam(req, "until")
+	if err != nil {
+		respondWithError(rw, 400, "Invalid until, must be an RFC 3339 timestamp")
+		return
+	}
+	if since.Valid && until.Valid && !since.Time.Before(until.Time) {
+		respondWithError(rw, 400, "since must be earlier than until")
+		return
+	}
+
+	cursorCreatedAt, cursorID := page.position()
+
+	// walking back through a page flips the order the rows are fetched in
+	var chirps []database.Chirp
+	if (sort == "asc") != page.backwards() {
+		chirps, err = cfg.db.ListChirpsAfter(req.Context(), database.ListChirpsAfterParams{
+			AuthorID:		authorID,
+			Since:			since,
+			Until:			until,
+			ViewerID:		viewer,
+			AfterCreatedAt:	cursorCreatedAt,
+			AfterID:		cursorID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		chirps, err = cfg.db.ListChirpsBefore(req.Context(), database.ListChirpsBeforeParams{
+			AuthorID:			authorID,
+			Since:				since,
+			Until:				until,
+			ViewerID:			viewer,
+			BeforeCreatedAt:	cursorCreatedAt,
+			BeforeID:			cursorID,
+			RowLimit:			page.limit + 1,
+		})
 	}
 
 	if err != nil {
@@ -83,15 +288,49 @@ func (cfg *apiConfig) handlerGetChirps(rw http.ResponseWriter, req *http.Request
 		return
 	}
 
-	mappedChirps := []Chirp{}
-	for _, c := range chirps {
-		mappedChirps = append(mappedChirps, Chirp(c))
+	chirps, next, prev := paginate(chirps, page, func(c database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirps")
+		return
+	}
+
+	// an author's pinned chirps lead the first page of their chirps, on top
+	// of showing up in their usual place
+	if authorID.Valid && page.cursor == nil && !since.Valid && !until.Valid {
+		blocked, err := cfg.isBlocked(req.Context(), viewer, authorID.UUID)
+		if err != nil {
+			log.Printf("Error checking the blocks of the author: %s", err)
+			respondWithError(rw, 500, "Can't retrieve chirps")
+			return
+		}
+		if !blocked {
+			pinned, err := cfg.pinnedChirps(req.Context(), viewer, authorID.UUID)
+			if err != nil {
+				log.Printf("Error retrieving the pinned chirps: %s", err)
+				respondWithError(rw, 500, "Can't retrieve chirps")
+				return
+			}
+			mappedChirps = append(pinned, mappedChirps...)
+		}
 	}
 	respondWithJSON(rw, 200, mappedChirps)
 	return
 }
 
 func (cfg *apiConfig) handlerGetChirp(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
 	cid := req.PathValue("chirpID")
 	if cid == "" {
 		respondWithError(rw, 400, "Missing chirp ID in request")
@@ -105,14 +344,24 @@ func (cfg *apiConfig) handlerGetChirp(rw http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	chirp, err := cfg.db.GetChirp(req.Context(), parsedUUID)
-	if err != nil {
+	chirp, err := cfg.visibleChirp(req.Context(), viewer, parsedUUID)
+	if errors.Is(err, sql.ErrNoRows) {
 		respondWithError(rw, 404, "Chirp not found")
 		return
 	}
+	if err != nil {
+		log.Printf("Error retrieving the chirp from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
 
-	mappedChirp := Chirp(chirp)
-	respondWithJSON(rw, 200, mappedChirp)
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps[0])
 }
 
 func (cfg *apiConfig) handlerDeleteChirp(rw http.ResponseWriter, req *http.Request) {
@@ -154,16 +403,448 @@ func (cfg *apiConfig) handlerDeleteChirp(rw http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	err = cfg.db.DeleteChirp(req.Context(), parsedUUID)
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
 	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
 		respondWithError(rw, 500, "Error deleting the chirp")
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.SoftDeleteChirp(req.Context(), parsedUUID)
+	if err != nil {
+		respondWithError(rw, 500, "Error deleting the chirp")
+		return
+	}
+
+	event, err := recordChirpEvent(req.Context(), qtx, chirpEventDeleted, chirp)
+	if err != nil {
+		log.Printf("Error recording the chirp event: %s", err)
+		respondWithError(rw, 500, "Error deleting the chirp")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the deletion: %s", err)
+		respondWithError(rw, 500, "Error deleting the chirp")
+		return
+	}
+	if event != nil {
+		cfg.hub.Publish(*event)
+	}
 
 	rw.WriteHeader(204)
 }
 
-func handlerValidateChirp(rw http.ResponseWriter, req *http.Request) {
+// chirpCheckError is a problem with a chirp that's up to the client to
+// fix, along with the status to respond with.
+type chirpCheckError struct {
+	code	int
+	msg		string
+}
+
+func (e *chirpCheckError) Error() string {
+	return e.msg
+}
+
+// respondToCheck responds for a failed check and reports whether it did.
+func respondToCheck(rw http.ResponseWriter, err error, failure string) bool {
+	if err == nil {
+		return false
+	}
+	var checkErr *chirpCheckError
+	if errors.As(err, &checkErr) {
+		respondWithError(rw, checkErr.code, checkErr.msg)
+		return true
+	}
+	log.Printf("%s: %s", failure, err)
+	respondWithError(rw, 500, failure)
+	return true
+}
+
+// visibleChirp looks up a chirp as the viewer sees it. Besides deleted and
+// hidden chirps and those of shadow-banned authors, chirps of authors the
+// viewer blocked or is blocked by aren't found either: blocks hide chirps
+// both ways, mutes only filter listings.
+func (cfg *apiConfig) visibleChirp(ctx context.Context, viewer uuid.NullUUID, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.db.GetVisibleChirp(ctx, database.GetVisibleChirpParams{ID: id, ViewerID: viewer})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	blocked, err := cfg.isBlocked(ctx, viewer, chirp.UserID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if blocked {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// checkReply makes sure the chirp being replied to, if any, exists and
+// that its author and the replier haven't blocked each other.
+func (cfg *apiConfig) checkReply(ctx context.Context, userID uuid.UUID, inReplyTo uuid.NullUUID) (database.Chirp, error) {
+	if !inReplyTo.Valid {
+		return database.Chirp{}, nil
+	}
+	parent, err := cfg.db.GetVisibleChirp(ctx, database.GetVisibleChirpParams{ID: inReplyTo.UUID, ViewerID: uuid.NullUUID{UUID: userID, Valid: true}})
+	if err != nil {
+		return database.Chirp{}, &chirpCheckError{400, "The chirp being replied to doesn't exist"}
+	}
+	blocked, err := cfg.isBlocked(ctx, uuid.NullUUID{UUID: userID, Valid: true}, parent.UserID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if blocked {
+		return database.Chirp{}, &chirpCheckError{403, "Not allowed to reply to this user"}
+	}
+	return parent, nil
+}
+
+// checkMedia drops repeated media IDs and makes sure the rest are the
+// user's own uploads, not attached to any chirp yet.
+func (cfg *apiConfig) checkMedia(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	mediaIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			mediaIDs = append(mediaIDs, id)
+		}
+	}
+	if len(mediaIDs) > maxChirpMedia {
+		return nil, &chirpCheckError{400, fmt.Sprintf("A chirp can have at most %d media", maxChirpMedia)}
+	}
+	if len(mediaIDs) == 0 {
+		return mediaIDs, nil
+	}
+
+	attachable, err := cfg.db.CountAttachableMedia(ctx, database.CountAttachableMediaParams{
+		Ids:	mediaIDs,
+		UserID:	userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if attachable != int64(len(mediaIDs)) {
+		return nil, &chirpCheckError{400, "Media must be your own uploads, not attached to another chirp"}
+	}
+	return mediaIDs, nil
+}
+
+// storeChirp writes a new chirp along with everything that hangs off it:
+// media, hashtags, mentions, notifications and the stream event. It's
+// meant to run in a transaction, the caller publishes the returned event
+// once it's committed.
+func storeChirp(ctx context.Context, db *database.Queries, params database.CreateChirpParams, mediaIDs []uuid.UUID) (database.Chirp, *stream.Event, error) {
+	chirp, err := db.CreateChirp(ctx, params)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	if len(mediaIDs) > 0 {
+		err = db.AttachChirpMedia(ctx, database.AttachChirpMediaParams{
+			ChirpID:	chirp.ID,
+			MediaIds:	mediaIDs,
+		})
+		if err != nil {
+			return database.Chirp{}, nil, err
+		}
+	}
+
+	err = saveHashtags(ctx, db, chirp)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	mentionedIDs, err := saveMentions(ctx, db, chirp)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	if chirp.InReplyTo.Valid {
+		parent, err := db.GetChirp(ctx, chirp.InReplyTo.UUID)
+		if err != nil {
+			return database.Chirp{}, nil, err
+		}
+		err = notify(ctx, db, parent.UserID, chirp.UserID, notificationKindReply, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			return database.Chirp{}, nil, err
+		}
+	}
+
+	err = notifyMentions(ctx, db, chirp.ID, mentionedIDs)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	event, err := recordChirpEvent(ctx, db, chirpEventCreated, chirp)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	return chirp, event, nil
+}
+
+// cleanChirpBody enforces the length limit and runs the body through the
+// profanity filter, it's run on every body that gets stored, new or
+// edited. It also reports whether the body should be reviewed.
+func (cfg *apiConfig) cleanChirpBody(body string) (string, bool, error) {
+	if len(body) > 140 {
+		return "", false, errors.New("Chirp is too long")
+	}
+	result := cfg.profanity.Load().Check(body)
+	if result.Rejected() {
+		return "", false, errors.New("Chirp contains words that aren't allowed")
+	}
+	return result.Text, result.Flagged(), nil
+}
+
+func mapChirp(chirp database.Chirp) Chirp {
+	threadID := chirp.ID
+	if chirp.ThreadID.Valid {
+		threadID = chirp.ThreadID.UUID
+	}
+	mapped := Chirp{
+		ID:			chirp.ID,
+		CreatedAt:	chirp.CreatedAt,
+		UpdatedAt:	chirp.UpdatedAt,
+		Body:		chirp.Body,
+		UserID:		chirp.UserID,
+		InReplyTo:	chirp.InReplyTo,
+		ThreadID:	threadID,
+		Kind:		chirp.Kind,
+		OriginalID:	chirp.OriginalID,
+	}
+	// deleted chirps only show up as placeholders in threads
+	if chirp.DeletedAt.Valid {
+		mapped.Body = ""
+		mapped.Deleted = true
+	}
+	return mapped
+}
+
+// mapChirps maps a page of chirps and embeds the originals of rechirps and
+// quotes, so clients don't have to look each of them up.
+func (cfg *apiConfig) mapChirps(ctx context.Context, viewer uuid.NullUUID, chirps []database.Chirp) ([]Chirp, error) {
+	mappedChirps, err := cfg.countChirps(ctx, viewer, chirps)
+	if err != nil {
+		return nil, err
+	}
+
+	originalIDs := []uuid.UUID{}
+	for _, c := range chirps {
+		if c.OriginalID.Valid {
+			originalIDs = append(originalIDs, c.OriginalID.UUID)
+		}
+	}
+	if len(originalIDs) == 0 {
+		return mappedChirps, nil
+	}
+
+	originals, err := cfg.db.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+		Ids:		originalIDs,
+		ViewerID:	viewer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mappedOriginals, err := cfg.countChirps(ctx, viewer, originals)
+	if err != nil {
+		return nil, err
+	}
+	originalsByID := map[uuid.UUID]*Chirp{}
+	for i := range mappedOriginals {
+		originalsByID[mappedOriginals[i].ID] = &mappedOriginals[i]
+	}
+
+	for i := range mappedChirps {
+		if mappedChirps[i].OriginalID.Valid {
+			mappedChirps[i].Original = originalsByID[mappedChirps[i].OriginalID.UUID]
+		}
+	}
+	return mappedChirps, nil
+}
+
+// countChirps maps chirps and fills in the counters that are stored in
+// other tables, with one query per counter for the whole page.
+// The viewer, when known, gets their own state on each chirp as well.
+func (cfg *apiConfig) countChirps(ctx context.Context, viewer uuid.NullUUID, chirps []database.Chirp) ([]Chirp, error) {
+	mappedChirps := []Chirp{}
+	ids := []uuid.UUID{}
+	for _, c := range chirps {
+		mappedChirps = append(mappedChirps, mapChirp(c))
+		ids = append(ids, c.ID)
+	}
+	if len(ids) == 0 {
+		return mappedChirps, nil
+	}
+
+	replies, err := cfg.db.CountReplies(ctx, database.CountRepliesParams{
+		ChirpIds:	ids,
+		ViewerID:	viewer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	replyCounts := map[uuid.UUID]int64{}
+	for _, r := range replies {
+		replyCounts[r.InReplyTo.UUID] = r.Replies
+	}
+
+	likes, err := cfg.db.CountLikes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	likeCounts := map[uuid.UUID]int64{}
+	for _, l := range likes {
+		likeCounts[l.ChirpID] = l.Likes
+	}
+
+	attached, err := cfg.db.ListChirpMedia(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	chirpMedia := map[uuid.UUID][]Media{}
+	for _, m := range attached {
+		chirpMedia[m.ChirpID] = append(chirpMedia[m.ChirpID], mapMedia(m.Medium))
+	}
+
+	polls, err := cfg.mapPolls(ctx, viewer, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	likedByViewer := map[uuid.UUID]bool{}
+	bookmarkedByViewer := map[uuid.UUID]bool{}
+	if viewer.Valid {
+		liked, err := cfg.db.ListLikedChirpIDs(ctx, database.ListLikedChirpIDsParams{UserID: viewer.UUID, ChirpIds: ids})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range liked {
+			likedByViewer[id] = true
+		}
+		bookmarked, err := cfg.db.ListBookmarkedChirpIDs(ctx, database.ListBookmarkedChirpIDsParams{UserID: viewer.UUID, ChirpIds: ids})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range bookmarked {
+			bookmarkedByViewer[id] = true
+		}
+	}
+
+	for i := range mappedChirps {
+		mappedChirps[i].ReplyCount = replyCounts[mappedChirps[i].ID]
+		mappedChirps[i].LikeCount = likeCounts[mappedChirps[i].ID]
+		mappedChirps[i].LikedByMe = likedByViewer[mappedChirps[i].ID]
+		mappedChirps[i].BookmarkedByMe = bookmarkedByViewer[mappedChirps[i].ID]
+		// deleted chirps don't show their media or poll any more than their body
+		if !mappedChirps[i].Deleted {
+			mappedChirps[i].Media = chirpMedia[mappedChirps[i].ID]
+			mappedChirps[i].Poll = polls[mappedChirps[i].ID]
+		}
+	}
+	return mappedChirps, nil
+}
+
+// handlerRestoreChirp brings back a deleted chirp, as long as its author
+// asks within the restore window.
+func (cfg *apiConfig) handlerRestoreChirp(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	cid := req.PathValue("chirpID")
+	if cid == "" {
+		respondWithError(rw, 400, "Missing chirp ID in request")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(cid)
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	chirp, err := cfg.db.GetDeletedChirp(req.Context(), parsedUUID)
+	if err != nil {
+		respondWithError(rw, 404, "Deleted chirp not found")
+		return
+	}
+
+	if chirp.UserID != userId {
+		respondWithError(rw, 403, "Not authorized to restore this chirp")
+		return
+	}
+
+	if chirp.HiddenAt.Valid {
+		respondWithError(rw, 403, "The chirp was taken down by a moderator")
+		return
+	}
+
+	if time.Since(chirp.DeletedAt.Time) > cfg.restoreWindow {
+		respondWithError(rw, 410, "The chirp can no longer be restored")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Error restoring the chirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err = qtx.RestoreChirp(req.Context(), chirp.ID)
+	if err != nil {
+		log.Printf("Error restoring the chirp: %s", err)
+		respondWithError(rw, 500, "Error restoring the chirp")
+		return
+	}
+
+	// clients that dropped the chirp on its deletion get it back as new
+	event, err := recordChirpEvent(req.Context(), qtx, chirpEventCreated, chirp)
+	if err != nil {
+		log.Printf("Error recording the chirp event: %s", err)
+		respondWithError(rw, 500, "Error restoring the chirp")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the restore: %s", err)
+		respondWithError(rw, 500, "Error restoring the chirp")
+		return
+	}
+	if event != nil {
+		cfg.hub.Publish(*event)
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps[0])
+}
+
+func (cfg *apiConfig) handlerValidateChirp(rw http.ResponseWriter, req *http.Request) {
 	type parameters struct {
 		Body string `json:"body"`
 	}
@@ -183,39 +864,10 @@ func handlerValidateChirp(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if len(params.Body) > 140 {
-		respondWithError(rw, 400, "Chirp is too long")
+	resBody.Cleaned_Body, _, err = cfg.cleanChirpBody(params.Body)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
 	} else {
-		resBody.Cleaned_Body = replaceProfaneWords(params.Body, getProfaneWords())
 		respondWithJSON(rw, 200, resBody)
 	}
-}
-
-func getProfaneWords() []string {
-	return []string{
-		"kerfuffle",
-		"sharbert",
-		"fornax",
-	}
-}
-
-func replaceProfaneWords(sentence string, profaneWords []string) string {
-	words := strings.Split(sentence, " ")
-	cleanWords := []string{}
-	for _, w := range words {
-		profane := false
-		for _, p := range profaneWords {
-			if strings.ToLower(w) == p {
-				profane = true
-				break
-			}
-		}
-		if profane {
-			cleanWords = append(cleanWords, "****")
-		} else {
-			cleanWords = append(cleanWords, w)
-		}
-	}
-	cleanSentence := strings.Join(cleanWords, " ")
-	return cleanSentence
 }
\ No newline at end of file
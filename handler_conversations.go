@@ -0,0 +1,442 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+const (
+	// counting the user who starts the conversation
+	maxConversationParticipants	= 10
+	maxMessageLength			= 1000
+)
+
+type Conversation struct {
+	ID				uuid.UUID	`json:"id"`
+	CreatedAt		time.Time	`json:"created_at"`
+	UpdatedAt		time.Time	`json:"updated_at"`
+	ParticipantIDs	[]uuid.UUID	`json:"participant_ids"`
+	LastReadAt		*time.Time	`json:"last_read_at,omitempty"`
+	UnreadCount		int64		`json:"unread_count"`
+}
+
+type Message struct {
+	ID				uuid.UUID	`json:"id"`
+	CreatedAt		time.Time	`json:"created_at"`
+	ConversationID	uuid.UUID	`json:"conversation_id"`
+	SenderID		uuid.UUID	`json:"sender_id"`
+	Body			string		`json:"body"`
+}
+
+// handlerCreateConversation starts a conversation between the caller and
+// the given users. Asking again for a one-to-one conversation that already
+// exists returns the existing one instead of opening a second.
+func (cfg *apiConfig) handlerCreateConversation(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		ParticipantIDs	[]uuid.UUID `json:"participant_ids"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	participants := []uuid.UUID{userId}
+	seen := map[uuid.UUID]bool{userId: true}
+	for _, id := range params.ParticipantIDs {
+		if !seen[id] {
+			seen[id] = true
+			participants = append(participants, id)
+		}
+	}
+	if len(participants) < 2 {
+		respondWithError(rw, 400, "A conversation needs at least one other participant")
+		return
+	}
+	if len(participants) > maxConversationParticipants {
+		respondWithError(rw, 400, "Too many participants")
+		return
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	for _, id := range participants[1:] {
+		_, err = cfg.db.GetUserByID(req.Context(), id)
+		if err != nil {
+			respondWithError(rw, 400, "Participant " + id.String() + " doesn't exist")
+			return
+		}
+		blocked, err := cfg.isBlocked(req.Context(), viewer, id)
+		if err != nil {
+			log.Printf("Error checking the blocks of the participants: %s", err)
+			respondWithError(rw, 500, "Can't create conversation")
+			return
+		}
+		if blocked {
+			respondWithError(rw, 403, "Not allowed to message this user")
+			return
+		}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't create conversation")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	if len(participants) == 2 {
+		// the lock lasts until the transaction ends, so two requests for the
+		// same pair can't both end up creating a conversation
+		pair := database.LockDirectConversationParams{UserID: userId, OtherID: participants[1]}
+		err = qtx.LockDirectConversation(req.Context(), pair)
+		if err != nil {
+			log.Printf("Error locking the conversation: %s", err)
+			respondWithError(rw, 500, "Can't create conversation")
+			return
+		}
+		conversation, err := qtx.FindDirectConversation(req.Context(), database.FindDirectConversationParams(pair))
+		if err == nil {
+			mappedConversations, err := cfg.mapConversations(req.Context(), []database.ListConversationsRow{{Conversation: conversation}})
+			if err != nil {
+				log.Printf("Error retrieving the participants of the conversation: %s", err)
+				respondWithError(rw, 500, "Can't retrieve conversation")
+				return
+			}
+			respondWithJSON(rw, 200, mappedConversations[0])
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error looking up the conversation: %s", err)
+			respondWithError(rw, 500, "Can't create conversation")
+			return
+		}
+	}
+
+	conversation, err := qtx.CreateConversation(req.Context())
+	if err != nil {
+		log.Printf("Error creating the conversation on the database: %s", err)
+		respondWithError(rw, 500, "Can't create conversation")
+		return
+	}
+
+	err = qtx.AddConversationParticipants(req.Context(), database.AddConversationParticipantsParams{
+		ConversationID:	conversation.ID,
+		UserIds:		participants,
+	})
+	if err != nil {
+		log.Printf("Error adding the participants of the conversation: %s", err)
+		respondWithError(rw, 500, "Can't create conversation")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the conversation: %s", err)
+		respondWithError(rw, 500, "Can't create conversation")
+		return
+	}
+
+	respondWithJSON(rw, 201, Conversation{
+		ID:				conversation.ID,
+		CreatedAt:		conversation.CreatedAt,
+		UpdatedAt:		conversation.UpdatedAt,
+		ParticipantIDs:	participants,
+	})
+}
+
+// handlerGetConversations lists the caller's conversations, the most
+// recently active first, each with the number of messages they haven't read.
+func (cfg *apiConfig) handlerGetConversations(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	conversations, err := cfg.db.ListConversations(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving the conversations from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve conversations")
+		return
+	}
+
+	mappedConversations, err := cfg.mapConversations(req.Context(), conversations)
+	if err != nil {
+		log.Printf("Error retrieving the participants of the conversations: %s", err)
+		respondWithError(rw, 500, "Can't retrieve conversations")
+		return
+	}
+	respondWithJSON(rw, 200, mappedConversations)
+}
+
+func (cfg *apiConfig) mapConversations(ctx context.Context, conversations []database.ListConversationsRow) ([]Conversation, error) {
+	mappedConversations := []Conversation{}
+	ids := []uuid.UUID{}
+	for _, c := range conversations {
+		mapped := Conversation{
+			ID:				c.Conversation.ID,
+			CreatedAt:		c.Conversation.CreatedAt,
+			UpdatedAt:		c.Conversation.UpdatedAt,
+			ParticipantIDs:	[]uuid.UUID{},
+			UnreadCount:	c.Unread,
+		}
+		if c.LastReadAt.Valid {
+			mapped.LastReadAt = &c.LastReadAt.Time
+		}
+		mappedConversations = append(mappedConversations, mapped)
+		ids = append(ids, c.Conversation.ID)
+	}
+	if len(ids) == 0 {
+		return mappedConversations, nil
+	}
+
+	participants, err := cfg.db.ListConversationParticipants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	participantsByID := map[uuid.UUID][]uuid.UUID{}
+	for _, p := range participants {
+		participantsByID[p.ConversationID] = append(participantsByID[p.ConversationID], p.UserID)
+	}
+
+	for i := range mappedConversations {
+		if p, ok := participantsByID[mappedConversations[i].ID]; ok {
+			mappedConversations[i].ParticipantIDs = p
+		}
+	}
+	return mappedConversations, nil
+}
+
+// conversationFromRequest authenticates the caller and loads the
+// conversation in the path, which must include them. It responds on its
+// own when either fails.
+func (cfg *apiConfig) conversationFromRequest(rw http.ResponseWriter, req *http.Request) (uuid.UUID, database.Conversation, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return uuid.Nil, database.Conversation{}, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return uuid.Nil, database.Conversation{}, false
+	}
+
+	conversationID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the conversation can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid conversation ID")
+		return uuid.Nil, database.Conversation{}, false
+	}
+
+	// other people's conversations are reported as missing, not forbidden
+	conversation, err := cfg.db.GetConversationForUser(req.Context(), database.GetConversationForUserParams{
+		ID:		conversationID,
+		UserID:	userId,
+	})
+	if err != nil {
+		respondWithError(rw, 404, "Conversation not found")
+		return uuid.Nil, database.Conversation{}, false
+	}
+	return userId, conversation, true
+}
+
+func (cfg *apiConfig) handlerCreateMessage(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body	string `json:"body"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	userId, conversation, ok := cfg.conversationFromRequest(rw, req)
+	if !ok {
+		return
+	}
+
+	if strings.TrimSpace(params.Body) == "" {
+		respondWithError(rw, 400, "Message can't be empty")
+		return
+	}
+	if len(params.Body) > maxMessageLength {
+		respondWithError(rw, 400, "Message is too long")
+		return
+	}
+
+	blocked, err := cfg.db.IsBlockedInConversation(req.Context(), database.IsBlockedInConversationParams{
+		UserID:			userId,
+		ConversationID:	conversation.ID,
+	})
+	if err != nil {
+		log.Printf("Error checking the blocks of the participants: %s", err)
+		respondWithError(rw, 500, "Can't send message")
+		return
+	}
+	if blocked {
+		respondWithError(rw, 403, "Not allowed to message this conversation")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't send message")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	message, err := qtx.CreateMessage(req.Context(), database.CreateMessageParams{
+		ConversationID:	conversation.ID,
+		SenderID:		userId,
+		Body:			params.Body,
+	})
+	if err != nil {
+		log.Printf("Error creating the message on the database: %s", err)
+		respondWithError(rw, 500, "Can't send message")
+		return
+	}
+
+	err = qtx.TouchConversation(req.Context(), conversation.ID)
+	if err != nil {
+		log.Printf("Error updating the conversation: %s", err)
+		respondWithError(rw, 500, "Can't send message")
+		return
+	}
+
+	// whoever writes has read everything up to their own message
+	err = qtx.MarkConversationRead(req.Context(), database.MarkConversationReadParams{
+		ConversationID:	conversation.ID,
+		UserID:			userId,
+	})
+	if err != nil {
+		log.Printf("Error updating the read marker: %s", err)
+		respondWithError(rw, 500, "Can't send message")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the message: %s", err)
+		respondWithError(rw, 500, "Can't send message")
+		return
+	}
+
+	respondWithJSON(rw, 201, Message(message))
+}
+
+// handlerGetMessages pages through a conversation, newest messages first.
+// Reading doesn't move the read marker, clients do that explicitly.
+func (cfg *apiConfig) handlerGetMessages(rw http.ResponseWriter, req *http.Request) {
+	_, conversation, ok := cfg.conversationFromRequest(rw, req)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	var messages []database.Message
+	if page.backwards() {
+		messages, err = cfg.db.ListMessagesAfter(req.Context(), database.ListMessagesAfterParams{
+			ConversationID:	conversation.ID,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		messages, err = cfg.db.ListMessagesBefore(req.Context(), database.ListMessagesBeforeParams{
+			ConversationID:		conversation.ID,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the messages from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve messages")
+		return
+	}
+
+	messages, next, prev := paginate(messages, page, func(m database.Message) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedMessages := []Message{}
+	for _, m := range messages {
+		mappedMessages = append(mappedMessages, Message(m))
+	}
+	respondWithJSON(rw, 200, mappedMessages)
+}
+
+func (cfg *apiConfig) handlerMarkConversationRead(rw http.ResponseWriter, req *http.Request) {
+	userId, conversation, ok := cfg.conversationFromRequest(rw, req)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.MarkConversationRead(req.Context(), database.MarkConversationReadParams{
+		ConversationID:	conversation.ID,
+		UserID:			userId,
+	})
+	if err != nil {
+		log.Printf("Error updating the read marker: %s", err)
+		respondWithError(rw, 500, "Can't mark conversation as read")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
\ No newline at end of file
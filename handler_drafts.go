@@ -0,0 +1,377 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+const (
+	draftStatusDraft		= "draft"
+	draftStatusScheduled	= "scheduled"
+	maxScheduleAhead		= 365 * 24 * time.Hour
+)
+
+type Draft struct {
+	ID			uuid.UUID		`json:"id"`
+	CreatedAt	time.Time		`json:"created_at"`
+	UpdatedAt	time.Time		`json:"updated_at"`
+	Body		string			`json:"body"`
+	InReplyTo	uuid.NullUUID	`json:"in_reply_to"`
+	MediaIDs	[]uuid.UUID		`json:"media_ids"`
+	PublishAt	*time.Time		`json:"publish_at"`
+	Status		string			`json:"status"`
+	Failure		string			`json:"failure,omitempty"`
+}
+
+func mapDraft(draft database.ChirpDraft) Draft {
+	mapped := Draft{
+		ID:			draft.ID,
+		CreatedAt:	draft.CreatedAt,
+		UpdatedAt:	draft.UpdatedAt,
+		Body:		draft.Body,
+		InReplyTo:	draft.InReplyTo,
+		MediaIDs:	draft.MediaIds,
+		Status:		draftStatusDraft,
+		Failure:	draft.Failure.String,
+	}
+	if draft.PublishAt.Valid {
+		mapped.PublishAt = &draft.PublishAt.Time
+		mapped.Status = draftStatusScheduled
+	}
+	return mapped
+}
+
+// draftParams is the body of both creating and editing a draft, an edit
+// replaces every field.
+type draftParams struct {
+	Body		string			`json:"body"`
+	InReplyTo	uuid.NullUUID	`json:"in_reply_to"`
+	MediaIDs	[]uuid.UUID		`json:"media_ids"`
+	PublishAt	*time.Time		`json:"publish_at"`
+}
+
+// checkDraft runs the same checks a chirp goes through, so a scheduled
+// chirp is unlikely to be turned down once it's due, and validates the
+// publish time.
+func (cfg *apiConfig) checkDraft(ctx context.Context, userID uuid.UUID, params *draftParams) (sql.NullTime, error) {
+	body, _, err := cfg.cleanChirpBody(params.Body)
+	if err != nil {
+		return sql.NullTime{}, &chirpCheckError{400, err.Error()}
+	}
+	params.Body = body
+
+	_, err = cfg.checkReply(ctx, userID, params.InReplyTo)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+
+	params.MediaIDs, err = cfg.checkMedia(ctx, userID, params.MediaIDs)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+
+	if params.PublishAt == nil {
+		return sql.NullTime{}, nil
+	}
+	publishAt := params.PublishAt.UTC()
+	if !publishAt.After(time.Now()) {
+		return sql.NullTime{}, &chirpCheckError{400, "Publish time must be in the future"}
+	}
+	if publishAt.After(time.Now().Add(maxScheduleAhead)) {
+		return sql.NullTime{}, &chirpCheckError{400, "Chirps can be scheduled at most a year ahead"}
+	}
+	return sql.NullTime{Time: publishAt, Valid: true}, nil
+}
+
+func (cfg *apiConfig) handlerCreateDraft(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	params := draftParams{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	publishAt, err := cfg.checkDraft(req.Context(), userId, &params)
+	if respondToCheck(rw, err, "Can't save draft") {
+		return
+	}
+
+	draft, err := cfg.db.CreateDraft(req.Context(), database.CreateDraftParams{
+		UserID:		userId,
+		Body:		params.Body,
+		InReplyTo:	params.InReplyTo,
+		MediaIds:	params.MediaIDs,
+		PublishAt:	publishAt,
+	})
+	if err != nil {
+		log.Printf("Error creating the draft on the database: %s", err)
+		respondWithError(rw, 500, "Can't save draft")
+		return
+	}
+	respondWithJSON(rw, 201, mapDraft(draft))
+}
+
+// handlerGetDrafts lists the caller's pending drafts, scheduled ones first
+// in the order they'll go out.
+func (cfg *apiConfig) handlerGetDrafts(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	drafts, err := cfg.db.ListDrafts(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving the drafts from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve drafts")
+		return
+	}
+
+	mappedDrafts := make([]Draft, len(drafts))
+	for i, d := range drafts {
+		mappedDrafts[i] = mapDraft(d)
+	}
+	respondWithJSON(rw, 200, mappedDrafts)
+}
+
+func (cfg *apiConfig) handlerUpdateDraft(rw http.ResponseWriter, req *http.Request) {
+	userId, draftID, ok := draftTarget(rw, req, cfg.tokenSecret)
+	if !ok {
+		return
+	}
+
+	params := draftParams{}
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	publishAt, err := cfg.checkDraft(req.Context(), userId, &params)
+	if respondToCheck(rw, err, "Can't save draft") {
+		return
+	}
+
+	// if the scheduler is publishing the draft right now, the update waits
+	// for it and then finds nothing to edit
+	draft, err := cfg.db.UpdateDraft(req.Context(), database.UpdateDraftParams{
+		ID:			draftID,
+		UserID:		userId,
+		Body:		params.Body,
+		InReplyTo:	params.InReplyTo,
+		MediaIds:	params.MediaIDs,
+		PublishAt:	publishAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Draft not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating the draft on the database: %s", err)
+		respondWithError(rw, 500, "Can't save draft")
+		return
+	}
+	respondWithJSON(rw, 200, mapDraft(draft))
+}
+
+// handlerDeleteDraft discards a draft, which also cancels it if it was
+// scheduled.
+func (cfg *apiConfig) handlerDeleteDraft(rw http.ResponseWriter, req *http.Request) {
+	userId, draftID, ok := draftTarget(rw, req, cfg.tokenSecret)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't delete draft")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	_, err = qtx.GetDraftForUpdate(req.Context(), database.GetDraftForUpdateParams{ID: draftID, UserID: userId})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Draft not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the draft from the database: %s", err)
+		respondWithError(rw, 500, "Can't delete draft")
+		return
+	}
+
+	err = qtx.DeleteDraft(req.Context(), draftID)
+	if err != nil {
+		log.Printf("Error deleting the draft from the database: %s", err)
+		respondWithError(rw, 500, "Can't delete draft")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the deletion of the draft: %s", err)
+		respondWithError(rw, 500, "Can't delete draft")
+		return
+	}
+	rw.WriteHeader(204)
+}
+
+// handlerPublishDraft publishes a draft right away, scheduled or not.
+func (cfg *apiConfig) handlerPublishDraft(rw http.ResponseWriter, req *http.Request) {
+	userId, draftID, ok := draftTarget(rw, req, cfg.tokenSecret)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't publish draft")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	draft, err := qtx.GetDraftForUpdate(req.Context(), database.GetDraftForUpdateParams{ID: draftID, UserID: userId})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Draft not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the draft from the database: %s", err)
+		respondWithError(rw, 500, "Can't publish draft")
+		return
+	}
+
+	chirp, event, err := cfg.publishDraft(req.Context(), qtx, draft)
+	if respondToCheck(rw, err, "Can't publish draft") {
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the chirp: %s", err)
+		respondWithError(rw, 500, "Can't publish draft")
+		return
+	}
+	if event != nil {
+		cfg.hub.Publish(*event)
+	}
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), uuid.NullUUID{UUID: userId, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 201, mappedChirps[0])
+}
+
+// publishDraft turns a locked draft into a chirp and drops the draft. The
+// checks are run again since the parent or the media may have changed
+// since the draft was saved, a failed check comes back as a
+// chirpCheckError.
+func (cfg *apiConfig) publishDraft(ctx context.Context, db *database.Queries, draft database.ChirpDraft) (database.Chirp, *stream.Event, error) {
+	// the word lists may have changed too
+	body, flagged, err := cfg.cleanChirpBody(draft.Body)
+	if err != nil {
+		return database.Chirp{}, nil, &chirpCheckError{400, err.Error()}
+	}
+	_, err = cfg.checkReply(ctx, draft.UserID, draft.InReplyTo)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	mediaIDs, err := cfg.checkMedia(ctx, draft.UserID, draft.MediaIds)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	chirp, event, err := storeChirp(ctx, db, database.CreateChirpParams{
+		Body:		body,
+		UserID:		draft.UserID,
+		InReplyTo:	draft.InReplyTo,
+		Kind:		chirpKindChirp,
+	}, mediaIDs)
+	if isUniqueViolation(err, "chirp_media_media_id_key") {
+		return database.Chirp{}, nil, &chirpCheckError{409, "Media is already attached to another chirp"}
+	}
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	err = db.DeleteDraft(ctx, draft.ID)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+	if flagged {
+		err = flagChirp(ctx, db, chirp)
+		if err != nil {
+			return database.Chirp{}, nil, err
+		}
+	}
+	return chirp, event, nil
+}
+
+// draftTarget authenticates the caller and parses the draft in the path,
+// it responds on its own when either fails.
+func draftTarget(rw http.ResponseWriter, req *http.Request, tokenSecret string) (uuid.UUID, uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userId, err := auth.ValidateJWT(token, tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		log.Printf("The ID of the draft can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid draft ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userId, draftID, true
+}
\ No newline at end of file
@@ -0,0 +1,275 @@
+package main
+
+import(
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+type FollowUser struct {
+	ID			uuid.UUID	`json:"id"`
+	Handle		string		`json:"handle,omitempty"`
+	FollowedAt	time.Time	`json:"followed_at"`
+}
+
+func (cfg *apiConfig) handlerFollowUser(rw http.ResponseWriter, req *http.Request) {
+	cfg.setFollow(rw, req, true)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(rw http.ResponseWriter, req *http.Request) {
+	cfg.setFollow(rw, req, false)
+}
+
+// setFollow is idempotent both ways, like setLike.
+func (cfg *apiConfig) setFollow(rw http.ResponseWriter, req *http.Request, follow bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	followeeID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return
+	}
+
+	if followeeID == userId {
+		respondWithError(rw, 400, "Users can't follow themselves")
+		return
+	}
+
+	_, err = cfg.db.GetUserByID(req.Context(), followeeID)
+	if err != nil {
+		respondWithError(rw, 404, "User not found")
+		return
+	}
+
+	if follow {
+		blocked, err := cfg.isBlocked(req.Context(), uuid.NullUUID{UUID: userId, Valid: true}, followeeID)
+		if err != nil {
+			log.Printf("Error checking the blocks of the user: %s", err)
+			respondWithError(rw, 500, "Can't update follow")
+			return
+		}
+		if blocked {
+			respondWithError(rw, 403, "Not allowed to follow this user")
+			return
+		}
+	}
+
+	var added int64
+	if follow {
+		added, err = cfg.db.FollowUser(req.Context(), database.FollowUserParams{FollowerID: userId, FolloweeID: followeeID})
+	} else {
+		_, err = cfg.db.UnfollowUser(req.Context(), database.UnfollowUserParams{FollowerID: userId, FolloweeID: followeeID})
+	}
+	if err != nil {
+		log.Printf("Error updating the follow on the database: %s", err)
+		respondWithError(rw, 500, "Can't update follow")
+		return
+	}
+
+	if added > 0 {
+		err = notify(req.Context(), cfg.db, followeeID, userId, notificationKindFollow, uuid.NullUUID{})
+		if err != nil {
+			log.Printf("Error notifying the followed user: %s", err)
+		}
+	}
+
+	rw.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(rw http.ResponseWriter, req *http.Request) {
+	cfg.listFollows(rw, req, true)
+}
+
+func (cfg *apiConfig) handlerGetFollowing(rw http.ResponseWriter, req *http.Request) {
+	cfg.listFollows(rw, req, false)
+}
+
+// listFollows pages through either side of a user's follows, most recent
+// first. The total count of that side is sent in X-Total-Count.
+func (cfg *apiConfig) listFollows(rw http.ResponseWriter, req *http.Request, followers bool) {
+	userID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	_, err = cfg.db.GetUserByID(req.Context(), userID)
+	if err != nil {
+		respondWithError(rw, 404, "User not found")
+		return
+	}
+
+	counts, err := cfg.db.CountFollows(req.Context(), userID)
+	if err != nil {
+		log.Printf("Error counting the follows: %s", err)
+		respondWithError(rw, 500, "Can't retrieve follows")
+		return
+	}
+
+	users, err := cfg.fetchFollows(req.Context(), userID, page, followers)
+	if err != nil {
+		log.Printf("Error retrieving the follows from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve follows")
+		return
+	}
+
+	users, next, prev := paginate(users, page, func(u database.ListFollowersBeforeRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: u.FollowedAt, ID: u.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	total := counts.Following
+	if followers {
+		total = counts.Followers
+	}
+	rw.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	mappedUsers := []FollowUser{}
+	for _, u := range users {
+		mappedUsers = append(mappedUsers, FollowUser{ID: u.ID, Handle: u.Handle.String, FollowedAt: u.FollowedAt})
+	}
+	respondWithJSON(rw, 200, mappedUsers)
+}
+
+func (cfg *apiConfig) fetchFollows(ctx context.Context, userID uuid.UUID, page pageParams, followers bool) ([]database.ListFollowersBeforeRow, error) {
+	users := []database.ListFollowersBeforeRow{}
+	beforeFollowedAt, beforeID := page.position()
+
+	switch {
+	case followers && page.backwards():
+		rows, err := cfg.db.ListFollowersAfter(ctx, database.ListFollowersAfterParams{
+			UserID:				userID,
+			AfterFollowedAt:	page.cursor.CreatedAt,
+			AfterID:			page.cursor.ID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			users = append(users, database.ListFollowersBeforeRow(r))
+		}
+	case followers:
+		return cfg.db.ListFollowersBefore(ctx, database.ListFollowersBeforeParams{
+			UserID:				userID,
+			BeforeFollowedAt:	beforeFollowedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	case page.backwards():
+		rows, err := cfg.db.ListFollowingAfter(ctx, database.ListFollowingAfterParams{
+			UserID:				userID,
+			AfterFollowedAt:	page.cursor.CreatedAt,
+			AfterID:			page.cursor.ID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			users = append(users, database.ListFollowersBeforeRow(r))
+		}
+	default:
+		rows, err := cfg.db.ListFollowingBefore(ctx, database.ListFollowingBeforeParams{
+			UserID:				userID,
+			BeforeFollowedAt:	beforeFollowedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			users = append(users, database.ListFollowersBeforeRow(r))
+		}
+	}
+	return users, nil
+}
+
+// handlerGetTimeline is the caller's home timeline: their own chirps and
+// those of everyone they follow, newest first.
+func (cfg *apiConfig) handlerGetTimeline(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	var chirps []database.Chirp
+	if page.backwards() {
+		chirps, err = cfg.db.ListTimelineAfter(req.Context(), database.ListTimelineAfterParams{
+			UserID:			userId,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		chirps, err = cfg.db.ListTimelineBefore(req.Context(), database.ListTimelineBeforeParams{
+			UserID:				userId,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the timeline from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve timeline")
+		return
+	}
+
+	chirps, next, prev := paginate(chirps, page, func(c database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve timeline")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps)
+}
\ No newline at end of file
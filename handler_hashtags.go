@@ -0,0 +1,154 @@
+package main
+
+import(
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/neriAle/chirpy/internal/chirptext"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+const defaultTrendingWindow = 24 * time.Hour
+const maxTrendingWindow = 7 * 24 * time.Hour
+const defaultTrendingLimit = 10
+
+type TrendingHashtag struct {
+	Tag		string	`json:"tag"`
+	Uses	int64	`json:"uses"`
+}
+
+// saveHashtags links a freshly stored chirp to the tags in its body,
+// creating the tags that have never been used before.
+func saveHashtags(ctx context.Context, db *database.Queries, chirp database.Chirp) error {
+	for _, tag := range chirptext.Hashtags(chirp.Body) {
+		hashtag, err := db.UpsertHashtag(ctx, tag)
+		if err != nil {
+			return err
+		}
+		err = db.AddChirpHashtag(ctx, database.AddChirpHashtagParams{ChirpID: chirp.ID, HashtagID: hashtag.ID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateHashtags brings the tags of an edited chirp in line with its body.
+// Tags that stay keep their original date, so an edit doesn't push an old
+// chirp back into trending.
+func updateHashtags(ctx context.Context, db *database.Queries, chirp database.Chirp) error {
+	err := db.DeleteStaleChirpHashtags(ctx, database.DeleteStaleChirpHashtagsParams{
+		ChirpID:	chirp.ID,
+		Tags:		chirptext.Hashtags(chirp.Body),
+	})
+	if err != nil {
+		return err
+	}
+	return saveHashtags(ctx, db, chirp)
+}
+
+func (cfg *apiConfig) handlerGetHashtagChirps(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	tag := chirptext.NormalizeTag(req.PathValue("tag"))
+	if tag == "" {
+		respondWithError(rw, 400, "Missing hashtag in request")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	// tag timelines are newest first, so only walking back fetches ascending
+	var chirps []database.Chirp
+	if page.backwards() {
+		chirps, err = cfg.db.ListHashtagChirpsAfter(req.Context(), database.ListHashtagChirpsAfterParams{
+			Tag:			tag,
+			ViewerID:		viewer,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		chirps, err = cfg.db.ListHashtagChirpsBefore(req.Context(), database.ListHashtagChirpsBeforeParams{
+			Tag:				tag,
+			ViewerID:			viewer,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the chirps of #%s: %s", tag, err)
+		respondWithError(rw, 500, "Can't retrieve chirps")
+		return
+	}
+
+	chirps, next, prev := paginate(chirps, page, func(c database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirps")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps)
+}
+
+// handlerGetTrendingHashtags ranks tags by how many chirps used them within
+// the sliding window, e.g. ?window=6h
+func (cfg *apiConfig) handlerGetTrendingHashtags(rw http.ResponseWriter, req *http.Request) {
+	window := defaultTrendingWindow
+	w := req.URL.Query().Get("window")
+	if w != "" {
+		parsed, err := time.ParseDuration(w)
+		if err != nil || parsed <= 0 || parsed > maxTrendingWindow {
+			respondWithError(rw, 400, "Invalid window, must be a duration of at most 168h")
+			return
+		}
+		window = parsed
+	}
+
+	limit := defaultTrendingLimit
+	l := req.URL.Query().Get("limit")
+	if l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > maxPageLimit {
+			respondWithError(rw, 400, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	tags, err := cfg.db.ListTrendingHashtags(req.Context(), database.ListTrendingHashtagsParams{
+		Since:		time.Now().UTC().Add(-window),
+		RowLimit:	int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error retrieving the trending hashtags: %s", err)
+		respondWithError(rw, 500, "Can't retrieve trending hashtags")
+		return
+	}
+
+	trending := []TrendingHashtag{}
+	for _, t := range tags {
+		trending = append(trending, TrendingHashtag(t))
+	}
+	respondWithJSON(rw, 200, trending)
+}
\ No newline at end of file
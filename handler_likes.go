@@ -0,0 +1,183 @@
+package main
+
+import(
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+func (cfg *apiConfig) handlerLikeChirp(rw http.ResponseWriter, req *http.Request) {
+	cfg.setLike(rw, req, true)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(rw http.ResponseWriter, req *http.Request) {
+	cfg.setLike(rw, req, false)
+}
+
+// setLike is idempotent both ways, liking twice or unliking a chirp that
+// wasn't liked leaves the like as requested. It responds with the chirp,
+// or with no content when unliking one that's no longer around.
+func (cfg *apiConfig) setLike(rw http.ResponseWriter, req *http.Request, liked bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	cid := req.PathValue("chirpID")
+	if cid == "" {
+		respondWithError(rw, 400, "Missing chirp ID in request")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(cid)
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	// unliking is always allowed, so neither a block nor a deleted chirp
+	// ever leaves a like stuck
+	if !liked {
+		_, err = cfg.db.UnlikeChirp(req.Context(), database.UnlikeChirpParams{UserID: userId, ChirpID: parsedUUID})
+		if err != nil {
+			log.Printf("Error updating the like on the database: %s", err)
+			respondWithError(rw, 500, "Can't update like")
+			return
+		}
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	chirp, err := cfg.db.GetVisibleChirp(req.Context(), database.GetVisibleChirpParams{ID: parsedUUID, ViewerID: viewer})
+	if err != nil && !liked {
+		rw.WriteHeader(204)
+		return
+	}
+	if err != nil {
+		respondWithError(rw, 404, "Chirp not found")
+		return
+	}
+
+	var added int64
+	if liked {
+		blocked, err := cfg.isBlocked(req.Context(), viewer, chirp.UserID)
+		if err != nil {
+			log.Printf("Error checking the blocks of the author: %s", err)
+			respondWithError(rw, 500, "Can't update like")
+			return
+		}
+		if blocked {
+			respondWithError(rw, 403, "Not allowed to like chirps of this user")
+			return
+		}
+
+		added, err = cfg.db.LikeChirp(req.Context(), database.LikeChirpParams{UserID: userId, ChirpID: chirp.ID})
+		if err != nil {
+			log.Printf("Error updating the like on the database: %s", err)
+			respondWithError(rw, 500, "Can't update like")
+			return
+		}
+	}
+
+	// the like is already stored, a missed notification isn't worth failing it
+	if added > 0 {
+		err = notify(req.Context(), cfg.db, chirp.UserID, userId, notificationKindLike, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			log.Printf("Error notifying the author of the chirp: %s", err)
+		}
+	}
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps[0])
+}
+
+// handlerGetUserLikes lists the chirps a user has liked, most recently liked first.
+func (cfg *apiConfig) handlerGetUserLikes(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	userID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	var likes []database.ListUserLikesBeforeRow
+	if page.backwards() {
+		rows, err := cfg.db.ListUserLikesAfter(req.Context(), database.ListUserLikesAfterParams{
+			UserID:			userID,
+			ViewerID:		viewer,
+			AfterLikedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the likes from the database: %s", err)
+			respondWithError(rw, 500, "Can't retrieve likes")
+			return
+		}
+		for _, r := range rows {
+			likes = append(likes, database.ListUserLikesBeforeRow(r))
+		}
+	} else {
+		beforeLikedAt, beforeID := page.position()
+		likes, err = cfg.db.ListUserLikesBefore(req.Context(), database.ListUserLikesBeforeParams{
+			UserID:			userID,
+			ViewerID:		viewer,
+			BeforeLikedAt:	beforeLikedAt,
+			BeforeID:		beforeID,
+			RowLimit:		page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the likes from the database: %s", err)
+			respondWithError(rw, 500, "Can't retrieve likes")
+			return
+		}
+	}
+
+	likes, next, prev := paginate(likes, page, func(l database.ListUserLikesBeforeRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: l.LikedAt, ID: l.Chirp.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	chirps := []database.Chirp{}
+	for _, l := range likes {
+		chirps = append(chirps, l.Chirp)
+	}
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve likes")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps)
+}
\ No newline at end of file
@@ -0,0 +1,199 @@
+package main
+
+import(
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/media"
+)
+
+const (
+	maxMediaSize	= 5 << 20
+	maxChirpMedia	= 4
+	// uploads that never make it onto a chirp or draft are purged after this long
+	unattachedMediaRetention	= 24 * time.Hour
+)
+
+type Media struct {
+	ID			uuid.UUID	`json:"id"`
+	CreatedAt	time.Time	`json:"created_at"`
+	URL			string		`json:"url"`
+	MimeType	string		`json:"mime_type"`
+	SizeBytes	int64		`json:"size_bytes"`
+	Width		int32		`json:"width"`
+	Height		int32		`json:"height"`
+}
+
+func mapMedia(m database.Medium) Media {
+	return Media{
+		ID:			m.ID,
+		CreatedAt:	m.CreatedAt,
+		URL:		"/media/" + m.ID.String(),
+		MimeType:	m.MimeType,
+		SizeBytes:	m.SizeBytes,
+		Width:		m.Width,
+		Height:		m.Height,
+	}
+}
+
+// handlerUploadMedia takes a single image in the "file" field of a
+// multipart form. The stored copy has its metadata stripped, and can be
+// attached to one of the uploader's chirps afterwards.
+func (cfg *apiConfig) handlerUploadMedia(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	// leave some room for the multipart framing around the file
+	req.Body = http.MaxBytesReader(rw, req.Body, maxMediaSize + 64 << 10)
+	err = req.ParseMultipartForm(maxMediaSize)
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			respondWithError(rw, 413, "File is too big")
+			return
+		}
+		respondWithError(rw, 400, "Expected a multipart form")
+		return
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		respondWithError(rw, 400, "Missing file in request")
+		return
+	}
+	defer file.Close()
+	if header.Size > maxMediaSize {
+		respondWithError(rw, 413, "File is too big")
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMediaSize + 1))
+	if err != nil {
+		log.Printf("Error reading the upload: %s", err)
+		respondWithError(rw, 500, "Can't upload file")
+		return
+	}
+	if len(data) > maxMediaSize {
+		respondWithError(rw, 413, "File is too big")
+		return
+	}
+
+	info, err := media.Inspect(data)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		respondWithError(rw, 415, "Only JPEG, PNG and GIF images are supported")
+		return
+	}
+	if err != nil {
+		respondWithError(rw, 400, "Invalid image")
+		return
+	}
+
+	data, err = media.StripMetadata(data, info.MIMEType)
+	if err != nil {
+		respondWithError(rw, 400, "Invalid image")
+		return
+	}
+
+	id := uuid.New()
+	key := id.String() + media.Extensions[info.MIMEType]
+	err = cfg.mediaStorage.Save(req.Context(), key, bytes.NewReader(data))
+	if err != nil {
+		log.Printf("Error storing the upload: %s", err)
+		respondWithError(rw, 500, "Can't upload file")
+		return
+	}
+
+	stored, err := cfg.db.CreateMedia(req.Context(), database.CreateMediaParams{
+		ID:			id,
+		UserID:		userId,
+		MimeType:	info.MIMEType,
+		SizeBytes:	int64(len(data)),
+		Width:		int32(info.Width),
+		Height:		int32(info.Height),
+		StorageKey:	key,
+	})
+	if err != nil {
+		log.Printf("Error creating the media on the database: %s", err)
+		cfg.mediaStorage.Delete(req.Context(), key)
+		respondWithError(rw, 500, "Can't upload file")
+		return
+	}
+
+	respondWithJSON(rw, 201, mapMedia(stored))
+}
+
+// handlerGetMedia serves an uploaded file as long as the chirp it's
+// attached to is visible, or to its uploader before it's attached. Files
+// never change, but their chirp can go away, so they're only cached for a
+// little while, and only privately when the answer depends on the viewer.
+func (cfg *apiConfig) handlerGetMedia(rw http.ResponseWriter, req *http.Request) {
+	mediaID, err := uuid.Parse(req.PathValue("mediaID"))
+	if err != nil {
+		respondWithError(rw, 400, "Invalid media ID")
+		return
+	}
+
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	m, err := cfg.db.GetVisibleMedia(req.Context(), database.GetVisibleMediaParams{
+		ID:			mediaID,
+		ViewerID:	viewer,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Media not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the media from the database: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	file, err := cfg.mediaStorage.Open(req.Context(), m.StorageKey)
+	if err != nil {
+		log.Printf("Error opening the stored file %s: %s", m.StorageKey, err)
+		respondWithError(rw, 404, "Media not found")
+		return
+	}
+	defer file.Close()
+
+	rw.Header().Set("Content-Type", m.MimeType)
+	rw.Header().Set("Content-Length", strconv.FormatInt(m.SizeBytes, 10))
+	if viewer.Valid {
+		rw.Header().Set("Cache-Control", "private, max-age=300")
+	} else {
+		rw.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(200)
+	_, err = io.Copy(rw, file)
+	if err != nil {
+		log.Printf("Error sending the file %s: %s", m.StorageKey, err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,123 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/chirptext"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+// saveMentions links a stored chirp to the users it mentions, handles
+// that don't belong to anyone are ignored. It returns the users that
+// weren't mentioned by the chirp before.
+func saveMentions(ctx context.Context, db *database.Queries, chirp database.Chirp) ([]uuid.UUID, error) {
+	return db.AddChirpMentions(ctx, database.AddChirpMentionsParams{
+		ChirpID:	chirp.ID,
+		Handles:	chirptext.Mentions(chirp.Body),
+	})
+}
+
+// updateMentions brings the mentions of an edited chirp in line with its
+// body and returns the users it mentions for the first time.
+func updateMentions(ctx context.Context, db *database.Queries, chirp database.Chirp) ([]uuid.UUID, error) {
+	err := db.DeleteStaleChirpMentions(ctx, database.DeleteStaleChirpMentionsParams{
+		ChirpID:	chirp.ID,
+		Handles:	chirptext.Mentions(chirp.Body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saveMentions(ctx, db, chirp)
+}
+
+// notifyMentions tells the given users that the chirp mentions them.
+func notifyMentions(ctx context.Context, db *database.Queries, chirpID uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	mentioned, err := db.NotifyMentions(ctx, database.NotifyMentionsParams{
+		ChirpID:	chirpID,
+		UserIds:	userIDs,
+	})
+	if err != nil {
+		return err
+	}
+	for _, n := range mentioned {
+		err = announceNotification(ctx, db, n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerGetMentions(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	handle := chirptext.NormalizeHandle(req.PathValue("handle"))
+	if !chirptext.ValidHandle(handle) {
+		respondWithError(rw, 400, "Invalid handle")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	user, err := cfg.db.GetUserByHandle(req.Context(), sql.NullString{String: handle, Valid: true})
+	if err != nil {
+		respondWithError(rw, 404, "User not found")
+		return
+	}
+
+	// mentions are listed newest first, so only walking back fetches ascending
+	var chirps []database.Chirp
+	if page.backwards() {
+		chirps, err = cfg.db.ListMentionChirpsAfter(req.Context(), database.ListMentionChirpsAfterParams{
+			UserID:			user.ID,
+			ViewerID:		viewer,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		chirps, err = cfg.db.ListMentionChirpsBefore(req.Context(), database.ListMentionChirpsBeforeParams{
+			UserID:				user.ID,
+			ViewerID:			viewer,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the mentions of @%s: %s", handle, err)
+		respondWithError(rw, 500, "Can't retrieve mentions")
+		return
+	}
+
+	chirps, next, prev := paginate(chirps, page, func(c database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve mentions")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps)
+}
\ No newline at end of file
@@ -0,0 +1,234 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+const (
+	notificationKindReply	= "reply"
+	notificationKindMention	= "mention"
+	notificationKindLike	= "like"
+	notificationKindFollow	= "follow"
+
+	// a grouped notification only lists the latest few actors
+	maxNotificationActors	= 5
+
+	// the kind of the events carrying notifications to live connections
+	notificationEvent		= "notification"
+)
+
+type Notification struct {
+	ID			uuid.UUID		`json:"id"`
+	CreatedAt	time.Time		`json:"created_at"`
+	UpdatedAt	time.Time		`json:"updated_at"`
+	Kind		string			`json:"kind"`
+	ChirpID		uuid.NullUUID	`json:"chirp_id"`
+	ActorIDs	[]uuid.UUID		`json:"actor_ids"`
+	ActorCount	int				`json:"actor_count"`
+	ReportID	uuid.NullUUID	`json:"report_id,omitempty"`
+	Read		bool			`json:"read"`
+}
+
+// notify records that actor did something to one of user's chirps, or to
+// user themselves for follows. Events repeating an unread notification are
+// grouped into it, and users never get notified about their own actions.
+func notify(ctx context.Context, db *database.Queries, userID, actorID uuid.UUID, kind string, chirpID uuid.NullUUID) error {
+	notification, err := db.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:		userID,
+		Kind:		kind,
+		ChirpID:	chirpID,
+		ActorID:	actorID,
+	})
+	// nothing is stored for the actor's own chirps, when they're muted or
+	// shadow-banned
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return announceNotification(ctx, db, notification)
+}
+
+// announceNotification pushes a new or updated notification to the
+// recipient's live connections on every server instance. Like the
+// notification itself, it only goes out once db's transaction commits.
+func announceNotification(ctx context.Context, db *database.Queries, notification database.Notification) error {
+	data, err := json.Marshal(mapNotification(notification))
+	if err != nil {
+		return err
+	}
+
+	event := stream.Event{
+		Kind:		notificationEvent,
+		Recipient:	uuid.NullUUID{UUID: notification.UserID, Valid: true},
+		Data:		data,
+	}
+	if len(notification.ActorIds) > 0 {
+		event.AuthorID = notification.ActorIds[len(notification.ActorIds) - 1]
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return db.Notify(ctx, database.NotifyParams{Channel: notificationsChannel, Payload: string(payload)})
+}
+
+func mapNotification(notification database.Notification) Notification {
+	// actors are stored oldest first, the latest are the interesting ones
+	actorIDs := []uuid.UUID{}
+	for i := len(notification.ActorIds) - 1; i >= 0 && len(actorIDs) < maxNotificationActors; i-- {
+		actorIDs = append(actorIDs, notification.ActorIds[i])
+	}
+	return Notification{
+		ID:			notification.ID,
+		CreatedAt:	notification.CreatedAt,
+		UpdatedAt:	notification.UpdatedAt,
+		Kind:		notification.Kind,
+		ChirpID:	notification.ChirpID,
+		ActorIDs:	actorIDs,
+		ActorCount:	len(notification.ActorIds),
+		ReportID:	notification.ReportID,
+		Read:		notification.ReadAt.Valid,
+	}
+}
+
+// handlerGetNotifications lists the caller's notifications, the most
+// recently updated first. unread=true leaves out the ones already seen and
+// the total of unread notifications is sent in X-Unread-Count.
+func (cfg *apiConfig) handlerGetNotifications(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	unreadOnly := false
+	s := req.URL.Query().Get("unread")
+	if s != "" {
+		unreadOnly, err = strconv.ParseBool(s)
+		if err != nil {
+			respondWithError(rw, 400, "Invalid unread, must be true or false")
+			return
+		}
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	var notifications []database.Notification
+	if page.backwards() {
+		notifications, err = cfg.db.ListNotificationsAfter(req.Context(), database.ListNotificationsAfterParams{
+			UserID:			userId,
+			UnreadOnly:		unreadOnly,
+			AfterUpdatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeUpdatedAt, beforeID := page.position()
+		notifications, err = cfg.db.ListNotificationsBefore(req.Context(), database.ListNotificationsBeforeParams{
+			UserID:				userId,
+			UnreadOnly:			unreadOnly,
+			BeforeUpdatedAt:	beforeUpdatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the notifications from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve notifications")
+		return
+	}
+
+	unread, err := cfg.db.CountUnreadNotifications(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error counting the unread notifications: %s", err)
+		respondWithError(rw, 500, "Can't retrieve notifications")
+		return
+	}
+
+	notifications, next, prev := paginate(notifications, page, func(n database.Notification) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: n.UpdatedAt, ID: n.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+	rw.Header().Set("X-Unread-Count", strconv.FormatInt(unread, 10))
+
+	mappedNotifications := []Notification{}
+	for _, n := range notifications {
+		mappedNotifications = append(mappedNotifications, mapNotification(n))
+	}
+	respondWithJSON(rw, 200, mappedNotifications)
+}
+
+// handlerMarkNotificationsRead marks the given notifications as seen, or
+// all of them when no IDs are sent.
+func (cfg *apiConfig) handlerMarkNotificationsRead(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		IDs	[]uuid.UUID `json:"ids"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	if len(params.IDs) == 0 {
+		_, err = cfg.db.MarkAllNotificationsRead(req.Context(), userId)
+	} else {
+		_, err = cfg.db.MarkNotificationsRead(req.Context(), database.MarkNotificationsReadParams{
+			UserID:	userId,
+			Ids:	params.IDs,
+		})
+	}
+	if err != nil {
+		log.Printf("Error marking the notifications as read: %s", err)
+		respondWithError(rw, 500, "Can't mark notifications as read")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
\ No newline at end of file
@@ -0,0 +1,246 @@
+package main
+
+import(
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+const (
+	maxPins				= 1
+	maxPinsChirpyRed	= 5
+)
+
+// handlerPinChirp pins one of the caller's chirps after the ones already
+// pinned. How many chirps can be pinned at once depends on the user's tier.
+func (cfg *apiConfig) handlerPinChirp(rw http.ResponseWriter, req *http.Request) {
+	userId, chirp, ok := cfg.pinTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't pin chirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// locking the user keeps concurrent pins from going over the limit
+	err = qtx.LockUser(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error locking the user: %s", err)
+		respondWithError(rw, 500, "Can't pin chirp")
+		return
+	}
+
+	user, err := qtx.GetUserByID(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving the user from the database: %s", err)
+		respondWithError(rw, 500, "Can't pin chirp")
+		return
+	}
+	limit := int64(maxPins)
+	if user.IsChirpyRed {
+		limit = maxPinsChirpyRed
+	}
+
+	pinned, err := qtx.CountPins(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error counting the pins on the database: %s", err)
+		respondWithError(rw, 500, "Can't pin chirp")
+		return
+	}
+
+	added, err := qtx.PinChirp(req.Context(), database.PinChirpParams{ChirpID: chirp.ID, UserID: userId})
+	if err != nil {
+		log.Printf("Error pinning the chirp on the database: %s", err)
+		respondWithError(rw, 500, "Can't pin chirp")
+		return
+	}
+	if added > 0 && pinned >= limit {
+		respondWithError(rw, 403, fmt.Sprintf("You can pin at most %d chirps", limit))
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the pin: %s", err)
+		respondWithError(rw, 500, "Can't pin chirp")
+		return
+	}
+	cfg.respondWithPins(rw, req, userId)
+}
+
+func (cfg *apiConfig) handlerUnpinChirp(rw http.ResponseWriter, req *http.Request) {
+	userId, chirp, ok := cfg.pinTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.UnpinChirp(req.Context(), database.UnpinChirpParams{ChirpID: chirp.ID, UserID: userId})
+	if err != nil {
+		log.Printf("Error unpinning the chirp on the database: %s", err)
+		respondWithError(rw, 500, "Can't unpin chirp")
+		return
+	}
+	cfg.respondWithPins(rw, req, userId)
+}
+
+// handlerReorderPins takes the caller's pinned chirps in their new order,
+// every pinned chirp has to be listed exactly once.
+func (cfg *apiConfig) handlerReorderPins(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		ChirpIDs	[]uuid.UUID	`json:"chirp_ids"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't reorder pins")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.LockUser(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error locking the user: %s", err)
+		respondWithError(rw, 500, "Can't reorder pins")
+		return
+	}
+
+	pinned, err := qtx.ListPinIDs(req.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving the pins from the database: %s", err)
+		respondWithError(rw, 500, "Can't reorder pins")
+		return
+	}
+	isPinned := map[uuid.UUID]bool{}
+	for _, id := range pinned {
+		isPinned[id] = true
+	}
+	listed := map[uuid.UUID]bool{}
+	for _, id := range params.ChirpIDs {
+		if !isPinned[id] || listed[id] {
+			respondWithError(rw, 400, "Chirp IDs must list each pinned chirp once")
+			return
+		}
+		listed[id] = true
+	}
+	if len(listed) != len(isPinned) {
+		respondWithError(rw, 400, "Chirp IDs must list each pinned chirp once")
+		return
+	}
+
+	err = qtx.ReorderPins(req.Context(), database.ReorderPinsParams{ChirpIds: params.ChirpIDs, UserID: userId})
+	if err != nil {
+		log.Printf("Error reordering the pins on the database: %s", err)
+		respondWithError(rw, 500, "Can't reorder pins")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the order of the pins: %s", err)
+		respondWithError(rw, 500, "Can't reorder pins")
+		return
+	}
+	cfg.respondWithPins(rw, req, userId)
+}
+
+// pinTarget authenticates the caller and resolves the chirp in the path,
+// which has to be one of theirs. It responds on its own when any of it fails.
+func (cfg *apiConfig) pinTarget(rw http.ResponseWriter, req *http.Request) (uuid.UUID, database.Chirp, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	parsedUUID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), parsedUUID)
+	if err != nil {
+		respondWithError(rw, 404, "Chirp not found")
+		return uuid.Nil, database.Chirp{}, false
+	}
+	if chirp.UserID != userId {
+		respondWithError(rw, 403, "Users can only pin their own chirps")
+		return uuid.Nil, database.Chirp{}, false
+	}
+	return userId, chirp, true
+}
+
+// respondWithPins responds with the user's pinned chirps in order.
+func (cfg *apiConfig) respondWithPins(rw http.ResponseWriter, req *http.Request, userID uuid.UUID) {
+	viewer := uuid.NullUUID{UUID: userID, Valid: true}
+	pinned, err := cfg.pinnedChirps(req.Context(), viewer, userID)
+	if err != nil {
+		log.Printf("Error retrieving the pinned chirps: %s", err)
+		respondWithError(rw, 500, "Can't retrieve pinned chirps")
+		return
+	}
+	respondWithJSON(rw, 200, pinned)
+}
+
+// pinnedChirps maps the chirps the author has pinned, in order, as the
+// viewer gets to see them.
+func (cfg *apiConfig) pinnedChirps(ctx context.Context, viewer uuid.NullUUID, authorID uuid.UUID) ([]Chirp, error) {
+	chirps, err := cfg.db.ListPinnedChirps(ctx, database.ListPinnedChirpsParams{UserID: authorID, ViewerID: viewer})
+	if err != nil {
+		return nil, err
+	}
+	mappedChirps, err := cfg.mapChirps(ctx, viewer, chirps)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mappedChirps {
+		mappedChirps[i].Pinned = true
+	}
+	return mappedChirps, nil
+}
\ No newline at end of file
@@ -0,0 +1,231 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/moderation"
+)
+
+const (
+	minPollOptions		= 2
+	maxPollOptions		= 4
+	maxPollOptionLength	= 25
+	minPollDuration		= 5 * time.Minute
+	maxPollDuration		= 7 * 24 * time.Hour
+)
+
+// Poll hides its tallies from viewers who haven't voted until it closes,
+// so early results don't sway the vote.
+type Poll struct {
+	ClosesAt	time.Time		`json:"closes_at"`
+	Closed		bool			`json:"closed"`
+	Options		[]PollOption	`json:"options"`
+	TotalVotes	*int64			`json:"total_votes,omitempty"`
+	VotedFor	*int32			`json:"voted_for,omitempty"`
+}
+
+type PollOption struct {
+	Position	int32	`json:"position"`
+	Text		string	`json:"text"`
+	Votes		*int64	`json:"votes,omitempty"`
+}
+
+type pollParams struct {
+	Options		[]string	`json:"options"`
+	ClosesAt	time.Time	`json:"closes_at"`
+}
+
+// cleanPoll validates the options and expiry of a new poll, running the
+// options through the profanity filter the same way as chirp bodies.
+func cleanPoll(filter *moderation.Filter, poll *pollParams) error {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return fmt.Errorf("A poll needs between %d and %d options", minPollOptions, maxPollOptions)
+	}
+	seen := map[string]bool{}
+	for i, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return errors.New("Poll options can't be empty")
+		}
+		if len([]rune(option)) > maxPollOptionLength {
+			return fmt.Errorf("Poll options can be at most %d characters long", maxPollOptionLength)
+		}
+		if seen[strings.ToLower(option)] {
+			return errors.New("Poll options must be different from each other")
+		}
+		seen[strings.ToLower(option)] = true
+		result := filter.Check(option)
+		if result.Rejected() {
+			return errors.New("Poll options contain words that aren't allowed")
+		}
+		poll.Options[i] = result.Text
+	}
+
+	duration := time.Until(poll.ClosesAt)
+	if duration < minPollDuration || duration > maxPollDuration {
+		return errors.New("A poll must stay open between 5 minutes and 7 days")
+	}
+	poll.ClosesAt = poll.ClosesAt.UTC()
+	return nil
+}
+
+// mapPolls looks up the polls on a page of chirps. The viewer, when known,
+// gets the option they voted for and, having voted, the tallies.
+func (cfg *apiConfig) mapPolls(ctx context.Context, viewer uuid.NullUUID, ids []uuid.UUID) (map[uuid.UUID]*Poll, error) {
+	options, err := cfg.db.ListPollOptions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	polls := map[uuid.UUID]*Poll{}
+	totals := map[uuid.UUID]int64{}
+	for _, o := range options {
+		poll, ok := polls[o.ChirpID]
+		if !ok {
+			poll = &Poll{
+				ClosesAt:	o.ClosesAt,
+				Closed:		!o.ClosesAt.After(time.Now()),
+				Options:	[]PollOption{},
+			}
+			polls[o.ChirpID] = poll
+		}
+		votes := o.Votes
+		poll.Options = append(poll.Options, PollOption{Position: o.Position, Text: o.Text, Votes: &votes})
+		totals[o.ChirpID] += o.Votes
+	}
+	if len(polls) == 0 {
+		return polls, nil
+	}
+
+	votedFor := map[uuid.UUID]int32{}
+	if viewer.Valid {
+		votes, err := cfg.db.ListPollVotes(ctx, database.ListPollVotesParams{UserID: viewer.UUID, ChirpIds: ids})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range votes {
+			votedFor[v.ChirpID] = v.Position
+		}
+	}
+
+	for id, poll := range polls {
+		position, voted := votedFor[id]
+		if voted {
+			poll.VotedFor = &position
+		}
+		if voted || poll.Closed {
+			total := totals[id]
+			poll.TotalVotes = &total
+			continue
+		}
+		for i := range poll.Options {
+			poll.Options[i].Votes = nil
+		}
+	}
+	return polls, nil
+}
+
+// handlerVotePoll records the caller's vote. Each user gets a single vote
+// per poll, which can't be changed afterwards.
+func (cfg *apiConfig) handlerVotePoll(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Position	int32	`json:"position"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	chirp, err := cfg.visibleChirp(req.Context(), viewer, parsedUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the chirp from the database: %s", err)
+		respondWithError(rw, 500, "Can't vote")
+		return
+	}
+
+	poll, err := cfg.db.GetPoll(req.Context(), chirp.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Chirp has no poll")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the poll from the database: %s", err)
+		respondWithError(rw, 500, "Can't vote")
+		return
+	}
+	if params.Position < 1 || int64(params.Position) > poll.Options {
+		respondWithError(rw, 400, "Invalid poll option")
+		return
+	}
+
+	// the vote only goes in while the poll is open and the user hasn't
+	// voted yet, checked in the same statement so concurrent votes can't
+	// slip past either
+	voted, err := cfg.db.VoteInPoll(req.Context(), database.VoteInPollParams{
+		UserID:		userId,
+		Position:	params.Position,
+		ChirpID:	chirp.ID,
+	})
+	if err != nil {
+		log.Printf("Error saving the vote on the database: %s", err)
+		respondWithError(rw, 500, "Can't vote")
+		return
+	}
+	if voted == 0 {
+		if !poll.ClosesAt.After(time.Now()) {
+			respondWithError(rw, 409, "Poll is closed")
+			return
+		}
+		respondWithError(rw, 409, "Already voted in this poll")
+		return
+	}
+
+	polls, err := cfg.mapPolls(req.Context(), viewer, []uuid.UUID{chirp.ID})
+	if err != nil {
+		log.Printf("Error retrieving the poll from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve poll")
+		return
+	}
+	respondWithJSON(rw, 201, polls[chirp.ID])
+}
\ No newline at end of file
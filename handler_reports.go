@@ -0,0 +1,508 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+const (
+	reportStatusOpen		= "open"
+	reportStatusDismissed	= "dismissed"
+	reportStatusActioned	= "actioned"
+
+	// the reason of the reports filed by the content filter
+	reportReasonFlagged		= "flagged"
+
+	moderationActionDismiss		= "dismiss"
+	moderationActionHideChirp	= "hide_chirp"
+	moderationActionSuspendUser	= "suspend_user"
+
+	maxReportDetailsLength	= 1000
+	maxSuspension			= 365 * 24 * time.Hour
+)
+
+// reportReasons are the categories users can pick from.
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "self_harm", "misinformation", "other"}
+
+type Report struct {
+	ID			uuid.UUID			`json:"id"`
+	CreatedAt	time.Time			`json:"created_at"`
+	UpdatedAt	time.Time			`json:"updated_at"`
+	ReporterID	uuid.NullUUID		`json:"reporter_id"`
+	UserID		uuid.UUID			`json:"user_id"`
+	ChirpID		uuid.NullUUID		`json:"chirp_id"`
+	Reason		string				`json:"reason"`
+	Details		string				`json:"details"`
+	Status		string				`json:"status"`
+	Resolution	*ReportResolution	`json:"resolution,omitempty"`
+}
+
+// ReportResolution is only shown to moderators, reporters just get to see
+// the status of their reports.
+type ReportResolution struct {
+	Action		string			`json:"action"`
+	ModeratorID	uuid.NullUUID	`json:"moderator_id"`
+	Note		string			`json:"note,omitempty"`
+}
+
+func mapReport(report database.Report) Report {
+	return Report{
+		ID:			report.ID,
+		CreatedAt:	report.CreatedAt,
+		UpdatedAt:	report.UpdatedAt,
+		ReporterID:	report.ReporterID,
+		UserID:		report.UserID,
+		ChirpID:	report.ChirpID,
+		Reason:		report.Reason,
+		Details:	report.Details,
+		Status:		report.Status,
+	}
+}
+
+// flagChirp files a report for a chirp the content filter wants reviewed.
+// A chirp that's already waiting for a review isn't queued again.
+func flagChirp(ctx context.Context, db *database.Queries, chirp database.Chirp) error {
+	return db.FlagChirp(ctx, database.FlagChirpParams{
+		UserID:		chirp.UserID,
+		ChirpID:	uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		Details:	"Flagged by the content filter",
+	})
+}
+
+// handlerCreateReport reports a chirp or a user. Reporting a chirp reports
+// its author as well, so moderators can act on either.
+func (cfg *apiConfig) handlerCreateReport(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		ChirpID	uuid.NullUUID	`json:"chirp_id"`
+		UserID	uuid.NullUUID	`json:"user_id"`
+		Reason	string			`json:"reason"`
+		Details	string			`json:"details"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	if !slices.Contains(reportReasons, params.Reason) {
+		respondWithError(rw, 400, "Invalid reason, must be one of " + strings.Join(reportReasons, ", "))
+		return
+	}
+	params.Details = strings.TrimSpace(params.Details)
+	if len([]rune(params.Details)) > maxReportDetailsLength {
+		respondWithError(rw, 400, fmt.Sprintf("Details can be at most %d characters long", maxReportDetailsLength))
+		return
+	}
+
+	if params.ChirpID.Valid {
+		chirp, err := cfg.db.GetVisibleChirp(req.Context(), database.GetVisibleChirpParams{
+			ID:			params.ChirpID.UUID,
+			ViewerID:	uuid.NullUUID{UUID: userId, Valid: true},
+		})
+		if err != nil {
+			respondWithError(rw, 404, "Chirp not found")
+			return
+		}
+		if params.UserID.Valid && params.UserID.UUID != chirp.UserID {
+			respondWithError(rw, 400, "The chirp doesn't belong to the reported user")
+			return
+		}
+		params.UserID = uuid.NullUUID{UUID: chirp.UserID, Valid: true}
+	} else if params.UserID.Valid {
+		_, err = cfg.db.GetUserByID(req.Context(), params.UserID.UUID)
+		if err != nil {
+			respondWithError(rw, 404, "User not found")
+			return
+		}
+	} else {
+		respondWithError(rw, 400, "Missing the chirp or user being reported")
+		return
+	}
+
+	if params.UserID.UUID == userId {
+		respondWithError(rw, 400, "Users can't report themselves")
+		return
+	}
+
+	report, err := cfg.db.CreateReport(req.Context(), database.CreateReportParams{
+		ReporterID:	uuid.NullUUID{UUID: userId, Valid: true},
+		UserID:		params.UserID.UUID,
+		ChirpID:	params.ChirpID,
+		Reason:		params.Reason,
+		Details:	params.Details,
+	})
+	if isUniqueViolation(err, "reports_open_key") {
+		respondWithError(rw, 409, "You already reported this and it's waiting for a review")
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating the report on the database: %s", err)
+		respondWithError(rw, 500, "Can't create report")
+		return
+	}
+	respondWithJSON(rw, 201, mapReport(report))
+}
+
+// handlerGetReports lists the caller's own reports, most recent first.
+func (cfg *apiConfig) handlerGetReports(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	reporter := uuid.NullUUID{UUID: userId, Valid: true}
+	var reports []database.Report
+	if page.backwards() {
+		reports, err = cfg.db.ListUserReportsAfter(req.Context(), database.ListUserReportsAfterParams{
+			ReporterID:		reporter,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		reports, err = cfg.db.ListUserReportsBefore(req.Context(), database.ListUserReportsBeforeParams{
+			ReporterID:			reporter,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving the reports from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve reports")
+		return
+	}
+
+	reports, next, prev := paginate(reports, page, func(r database.Report) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedReports := []Report{}
+	for _, r := range reports {
+		mappedReports = append(mappedReports, mapReport(r))
+	}
+	respondWithJSON(rw, 200, mappedReports)
+}
+
+// handlerGetModerationQueue lists reports with the given status, open ones
+// by default, most recent first.
+func (cfg *apiConfig) handlerGetModerationQueue(rw http.ResponseWriter, req *http.Request) {
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		status = reportStatusOpen
+	}
+	if status != reportStatusOpen && status != reportStatusDismissed && status != reportStatusActioned {
+		respondWithError(rw, 400, "Invalid status, must be open, dismissed or actioned")
+		return
+	}
+
+	var rows []database.ListReportsBeforeRow
+	if page.backwards() {
+		after, err := cfg.db.ListReportsAfter(req.Context(), database.ListReportsAfterParams{
+			Status:			status,
+			AfterCreatedAt:	page.cursor.CreatedAt,
+			AfterID:		page.cursor.ID,
+			RowLimit:		page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the reports from the database: %s", err)
+			respondWithError(rw, 500, "Can't retrieve reports")
+			return
+		}
+		for _, r := range after {
+			rows = append(rows, database.ListReportsBeforeRow(r))
+		}
+	} else {
+		beforeCreatedAt, beforeID := page.position()
+		rows, err = cfg.db.ListReportsBefore(req.Context(), database.ListReportsBeforeParams{
+			Status:				status,
+			BeforeCreatedAt:	beforeCreatedAt,
+			BeforeID:			beforeID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the reports from the database: %s", err)
+			respondWithError(rw, 500, "Can't retrieve reports")
+			return
+		}
+	}
+
+	rows, next, prev := paginate(rows, page, func(r database.ListReportsBeforeRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: r.Report.CreatedAt, ID: r.Report.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	mappedReports := []Report{}
+	for _, r := range rows {
+		report := mapReport(r.Report)
+		if r.Action.Valid {
+			report.Resolution = &ReportResolution{
+				Action:			r.Action.String,
+				ModeratorID:	r.ModeratorID,
+				Note:			r.Note.String,
+			}
+		}
+		mappedReports = append(mappedReports, report)
+	}
+	respondWithJSON(rw, 200, mappedReports)
+}
+
+// handlerResolveReport acts on an open report. Dismissing only closes the
+// report itself, hiding the chirp or suspending the user also closes every
+// other open report about the same chirp or user. Suspending follows the
+// same rules as the suspend endpoint. Each reporter whose report gets
+// closed is notified.
+func (cfg *apiConfig) handlerResolveReport(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Action		string	`json:"action"`
+		Note		string	`json:"note"`
+		Duration	string	`json:"duration"`
+	}
+
+	moderator, ok := staffFromContext(req.Context())
+	if !ok {
+		respondWithError(rw, 403, "Not allowed to access this resource")
+		return
+	}
+
+	reportID, err := uuid.Parse(req.PathValue("reportID"))
+	if err != nil {
+		log.Printf("The ID of the report can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid report ID")
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+	params.Note = strings.TrimSpace(params.Note)
+
+	var suspension time.Duration
+	switch params.Action {
+	case moderationActionDismiss, moderationActionHideChirp:
+	case moderationActionSuspendUser:
+		suspension, err = parseSuspension(params.Duration)
+		if err != nil {
+			respondWithError(rw, 400, err.Error())
+			return
+		}
+	default:
+		respondWithError(rw, 400, "Invalid action, must be dismiss, hide_chirp or suspend_user")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't resolve report")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.GetReportForUpdate(req.Context(), reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Report not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the report from the database: %s", err)
+		respondWithError(rw, 500, "Can't resolve report")
+		return
+	}
+	if report.Status != reportStatusOpen {
+		respondWithError(rw, 409, "Report was already resolved")
+		return
+	}
+
+	status := reportStatusActioned
+	ids := []uuid.UUID{report.ID}
+	var event *stream.Event
+	switch params.Action {
+	case moderationActionDismiss:
+		status = reportStatusDismissed
+	case moderationActionHideChirp:
+		if !report.ChirpID.Valid {
+			respondWithError(rw, 400, "The report isn't about a chirp")
+			return
+		}
+		event, err = hideChirp(req.Context(), qtx, report.ChirpID.UUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(rw, 404, "Chirp not found")
+			return
+		}
+		if err == nil {
+			ids, err = qtx.ListOpenReportIDs(req.Context(), database.ListOpenReportIDsParams{UserID: report.UserID, ChirpID: report.ChirpID})
+		}
+	case moderationActionSuspendUser:
+		var target database.User
+		target, err = qtx.GetUserByID(req.Context(), report.UserID)
+		if err != nil {
+			log.Printf("Error retrieving the user from the database: %s", err)
+			respondWithError(rw, 500, "Can't resolve report")
+			return
+		}
+		if !canModerate(rw, moderator, target) {
+			return
+		}
+
+		reason := params.Note
+		if reason == "" {
+			reason = report.Reason
+		}
+		_, err = suspendUser(req.Context(), qtx, report.UserID, moderator.ID, reason, suspension)
+		if err == nil {
+			ids, err = qtx.ListOpenReportIDs(req.Context(), database.ListOpenReportIDsParams{UserID: report.UserID})
+		}
+	}
+	if err != nil {
+		log.Printf("Error acting on the report: %s", err)
+		respondWithError(rw, 500, "Can't resolve report")
+		return
+	}
+
+	action, err := qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID:	uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		Action:			params.Action,
+		ChirpID:		report.ChirpID,
+		UserID:			uuid.NullUUID{UUID: report.UserID, Valid: true},
+		Note:			params.Note,
+	})
+	if err != nil {
+		log.Printf("Error recording the moderation action: %s", err)
+		respondWithError(rw, 500, "Can't resolve report")
+		return
+	}
+
+	resolved, err := qtx.ResolveReports(req.Context(), database.ResolveReportsParams{
+		Status:		status,
+		ActionID:	uuid.NullUUID{UUID: action.ID, Valid: true},
+		Ids:		ids,
+	})
+	if err != nil {
+		log.Printf("Error resolving the reports on the database: %s", err)
+		respondWithError(rw, 500, "Can't resolve report")
+		return
+	}
+
+	for _, r := range resolved {
+		if r.ID == report.ID {
+			report = r
+		}
+		// reports filed by the content filter have nobody to tell
+		if !r.ReporterID.Valid {
+			continue
+		}
+		notification, err := qtx.NotifyReport(req.Context(), database.NotifyReportParams{
+			UserID:		r.ReporterID.UUID,
+			ReportID:	uuid.NullUUID{UUID: r.ID, Valid: true},
+		})
+		if err == nil {
+			err = announceNotification(req.Context(), qtx, notification)
+		}
+		if err != nil {
+			log.Printf("Error notifying the reporter: %s", err)
+			respondWithError(rw, 500, "Can't resolve report")
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the resolution: %s", err)
+		respondWithError(rw, 500, "Can't resolve report")
+		return
+	}
+	if event != nil {
+		cfg.hub.Publish(*event)
+	}
+
+	mapped := mapReport(report)
+	mapped.Resolution = &ReportResolution{
+		Action:			action.Action,
+		ModeratorID:	action.ModeratorID,
+		Note:			action.Note,
+	}
+	respondWithJSON(rw, 200, mapped)
+}
+
+// hideChirp takes a chirp down for good, its author can't restore it. A
+// chirp that was still up goes out as deleted to live connections, the
+// returned event is published once db's transaction commits.
+func hideChirp(ctx context.Context, db *database.Queries, chirpID uuid.UUID) (*stream.Event, error) {
+	_, err := db.GetChirp(ctx, chirpID)
+	visible := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	chirp, err := db.HideChirp(ctx, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, nil
+	}
+
+	return recordChirpEvent(ctx, db, chirpEventDeleted, chirp)
+}
\ No newline at end of file
@@ -0,0 +1,216 @@
+package main
+
+import(
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+type ChirpRevision struct {
+	Body		string		`json:"body"`
+	WrittenAt	time.Time	`json:"written_at"`
+	ReplacedAt	*time.Time	`json:"replaced_at"`
+}
+
+// handlerUpdateChirp replaces the body of a chirp, keeping the previous
+// version in its history. Hashtags and mentions follow the new body, and
+// users mentioned for the first time are notified.
+func (cfg *apiConfig) handlerUpdateChirp(rw http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	cid := req.PathValue("chirpID")
+	if cid == "" {
+		respondWithError(rw, 400, "Missing chirp ID in request")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(cid)
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	type parameters struct {
+		Body	string `json:"body"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 400, "Body is required for editing")
+		return
+	}
+
+	body, flagged, err := cfg.cleanChirpBody(params.Body)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't update chirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// the row stays locked until commit so concurrent edits can't lose a revision
+	chirp, err := qtx.GetChirpForUpdate(req.Context(), parsedUUID)
+	if err != nil {
+		respondWithError(rw, 404, "Chirp not found")
+		return
+	}
+
+	if chirp.UserID != userId {
+		respondWithError(rw, 403, "Not authorized to edit this chirp")
+		return
+	}
+
+	if chirp.Kind == chirpKindRechirp {
+		respondWithError(rw, 400, "Rechirps can't be edited")
+		return
+	}
+	if chirp.Kind == chirpKindQuote && body == "" {
+		respondWithError(rw, 400, "A quote needs some commentary")
+		return
+	}
+
+	if body == chirp.Body {
+		respondWithError(rw, 400, "The new body is the same as the current one")
+		return
+	}
+
+	err = qtx.CreateChirpRevision(req.Context(), database.CreateChirpRevisionParams{
+		ChirpID:	chirp.ID,
+		Body:		chirp.Body,
+		WrittenAt:	chirp.UpdatedAt,
+	})
+	if err != nil {
+		log.Printf("Error storing the chirp revision: %s", err)
+		respondWithError(rw, 500, "Can't update chirp")
+		return
+	}
+
+	chirp, err = qtx.UpdateChirpBody(req.Context(), database.UpdateChirpBodyParams{ID: chirp.ID, Body: body})
+	if err != nil {
+		log.Printf("Error updating the chirp on the database: %s", err)
+		respondWithError(rw, 500, "Can't update chirp")
+		return
+	}
+
+	err = updateHashtags(req.Context(), qtx, chirp)
+	if err != nil {
+		log.Printf("Error storing the hashtags of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't update chirp")
+		return
+	}
+
+	mentionedIDs, err := updateMentions(req.Context(), qtx, chirp)
+	if err == nil {
+		err = notifyMentions(req.Context(), qtx, chirp.ID, mentionedIDs)
+	}
+	if err != nil {
+		log.Printf("Error storing the mentions of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't update chirp")
+		return
+	}
+
+	if flagged {
+		err = flagChirp(req.Context(), qtx, chirp)
+		if err != nil {
+			log.Printf("Error flagging the chirp for review: %s", err)
+			respondWithError(rw, 500, "Can't update chirp")
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the chirp: %s", err)
+		respondWithError(rw, 500, "Can't update chirp")
+		return
+	}
+
+	viewer := uuid.NullUUID{UUID: userId, Valid: true}
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirp: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps[0])
+}
+
+// handlerGetChirpHistory lists every version of a chirp, oldest first and
+// ending with the current one.
+func (cfg *apiConfig) handlerGetChirpHistory(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	cid := req.PathValue("chirpID")
+	if cid == "" {
+		respondWithError(rw, 400, "Missing chirp ID in request")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(cid)
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	chirp, err := cfg.visibleChirp(req.Context(), viewer, parsedUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the chirp from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp history")
+		return
+	}
+
+	revisions, err := cfg.db.ListChirpRevisions(req.Context(), chirp.ID)
+	if err != nil {
+		log.Printf("Error retrieving the chirp history: %s", err)
+		respondWithError(rw, 500, "Can't retrieve chirp history")
+		return
+	}
+
+	history := []ChirpRevision{}
+	for _, r := range revisions {
+		replacedAt := r.CreatedAt
+		history = append(history, ChirpRevision{Body: r.Body, WrittenAt: r.WrittenAt, ReplacedAt: &replacedAt})
+	}
+	history = append(history, ChirpRevision{Body: chirp.Body, WrittenAt: chirp.UpdatedAt})
+	respondWithJSON(rw, 200, history)
+}
\ No newline at end of file
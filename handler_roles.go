@@ -0,0 +1,172 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+// staffKey is the context key for the user let through by
+// middlewareRequireRole.
+type staffKey struct{}
+
+// staffFromContext returns the user let through by middlewareRequireRole.
+func staffFromContext(ctx context.Context) (database.User, bool) {
+	user, ok := ctx.Value(staffKey{}).(database.User)
+	return user, ok
+}
+
+// middlewareRequireRole only lets through requests from users with at
+// least the required role. The user is looked up once per request, so
+// demoted and suspended users lose access right away, and kept in the
+// context for nested checks and the handlers.
+func (cfg *apiConfig) middlewareRequireRole(role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if user, ok := staffFromContext(req.Context()); ok {
+			if !auth.HasRole(user.Role, role) {
+				respondWithError(rw, 403, "Not allowed to access this resource")
+				return
+			}
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		token, err := auth.GetBearerToken(req.Header)
+		if err != nil {
+			log.Printf("Header is missing JWT: %s", err)
+			respondWithError(rw, 401, "Header is missing JWT")
+			return
+		}
+
+		userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+		if err != nil {
+			log.Printf("Invalid token: %s", err)
+			respondWithError(rw, 401, "JWT is not valid")
+			return
+		}
+
+		user, err := cfg.db.GetUserByID(req.Context(), userId)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(rw, 401, "JWT is not valid")
+			return
+		}
+		if err != nil {
+			log.Printf("Error retrieving the user from the database: %s", err)
+			respondWithError(rw, 500, "Something went wrong")
+			return
+		}
+
+		if !auth.HasRole(user.Role, role) {
+			respondWithError(rw, 403, "Not allowed to access this resource")
+			return
+		}
+		if cfg.respondIfSuspended(rw, req.Context(), user.ID) {
+			return
+		}
+		next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), staffKey{}, user)))
+	})
+}
+
+// handlerSetUserRole changes the role of a user. Admins can't change their
+// own role, and the last admin can't be demoted. The admins are locked
+// while the role changes, so two of them can't demote each other at once.
+func (cfg *apiConfig) handlerSetUserRole(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Role	string	`json:"role"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		log.Printf("Header is missing JWT: %s", err)
+		respondWithError(rw, 401, "Header is missing JWT")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	targetID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	if !auth.ValidRole(params.Role) {
+		respondWithError(rw, 400, "Invalid role, must be user, moderator or admin")
+		return
+	}
+	if targetID == userId {
+		respondWithError(rw, 400, "Admins can't change their own role")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't update role")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	admins, err := qtx.ListAdminIDsForUpdate(req.Context())
+	if err != nil {
+		log.Printf("Error retrieving the admins from the database: %s", err)
+		respondWithError(rw, 500, "Can't update role")
+		return
+	}
+	if params.Role != auth.RoleAdmin && slices.Contains(admins, targetID) && len(admins) == 1 {
+		respondWithError(rw, 409, "Can't demote the last admin")
+		return
+	}
+
+	user, err := qtx.SetUserRole(req.Context(), database.SetUserRoleParams{ID: targetID, Role: params.Role})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "User not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating the role on the database: %s", err)
+		respondWithError(rw, 500, "Can't update role")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the transaction: %s", err)
+		respondWithError(rw, 500, "Can't update role")
+		return
+	}
+
+	respondWithJSON(rw, 200, User{
+		ID:				user.ID,
+		CreatedAt:		user.CreatedAt,
+		UpdatedAt:		user.UpdatedAt,
+		Email:			user.Email,
+		IsChirpyRed:	user.IsChirpyRed,
+		Handle:			user.Handle.String,
+		Role:			user.Role,
+	})
+}
\ No newline at end of file
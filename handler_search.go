@@ -0,0 +1,97 @@
+package main
+
+import(
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+// handlerSearchChirps ranks matching chirps by relevance. The query uses
+// websearch syntax, so "quoted phrases", OR and -negation all work.
+func (cfg *apiConfig) handlerSearchChirps(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	query := strings.TrimSpace(req.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(rw, 400, "Missing search query")
+		return
+	}
+
+	page, err := parsePageParams(req)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	authorID := uuid.NullUUID{}
+	s := req.URL.Query().Get("author_id")
+	if s != "" {
+		parsedUUID, err := uuid.Parse(s)
+		if err != nil {
+			log.Printf("The ID of the user can't be parsed into a UUID")
+			respondWithError(rw, 400, "Invalid user ID")
+			return
+		}
+		authorID = uuid.NullUUID{UUID: parsedUUID, Valid: true}
+	}
+
+	var results []database.SearchChirpsAfterRow
+	if page.backwards() {
+		rows, err := cfg.db.SearchChirpsBefore(req.Context(), database.SearchChirpsBeforeParams{
+			Query:				query,
+			ViewerID:			viewer,
+			AuthorID:			authorID,
+			BeforeRank:			page.cursor.Rank,
+			BeforeCreatedAt:	page.cursor.CreatedAt,
+			BeforeID:			page.cursor.ID,
+			RowLimit:			page.limit + 1,
+		})
+		if err != nil {
+			log.Printf("Error searching the chirps on the database: %s", err)
+			respondWithError(rw, 500, "Can't search chirps")
+			return
+		}
+		for _, r := range rows {
+			results = append(results, database.SearchChirpsAfterRow(r))
+		}
+	} else {
+		params := database.SearchChirpsAfterParams{Query: query, AuthorID: authorID, ViewerID: viewer, RowLimit: page.limit + 1}
+		if page.cursor != nil {
+			params.AfterCreatedAt, params.AfterID = page.position()
+			params.AfterRank.Float64 = float64(page.cursor.Rank)
+			params.AfterRank.Valid = true
+		}
+		results, err = cfg.db.SearchChirpsAfter(req.Context(), params)
+		if err != nil {
+			log.Printf("Error searching the chirps on the database: %s", err)
+			respondWithError(rw, 500, "Can't search chirps")
+			return
+		}
+	}
+
+	results, next, prev := paginate(results, page, func(r database.SearchChirpsAfterRow) pagination.Cursor {
+		return pagination.Cursor{Rank: r.Rank, CreatedAt: r.Chirp.CreatedAt, ID: r.Chirp.ID}
+	})
+	setPaginationLinks(rw, req, next, prev)
+
+	chirps := []database.Chirp{}
+	for _, r := range results {
+		chirps = append(chirps, r.Chirp)
+	}
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the chirps: %s", err)
+		respondWithError(rw, 500, "Can't search chirps")
+		return
+	}
+	respondWithJSON(rw, 200, mappedChirps)
+}
\ No newline at end of file
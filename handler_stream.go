@@ -0,0 +1,187 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+const (
+	chirpEventCreated	= "chirp.created"
+	chirpEventDeleted	= "chirp.deleted"
+
+	// the PostgreSQL channels events are announced on
+	chirpEventsChannel		= "chirp_events"
+	notificationsChannel	= "notifications"
+	// how long events are kept around for clients resuming a stream
+	chirpEventRetention	= 24 * time.Hour
+	// clients that were away for longer than this many events miss the rest
+	maxReplayEvents		= 1000
+	streamHeartbeat		= 15 * time.Second
+)
+
+// recordChirpEvent stores an event about a chirp and announces it to every
+// server instance. Both happen in db's transaction, so the announcement
+// only goes out once the change is committed. The caller publishes the
+// returned event to its own hub after committing. Shadow-banned authors'
+// chirps don't make it to any stream, there's no event for them.
+func recordChirpEvent(ctx context.Context, db *database.Queries, kind string, chirp database.Chirp) (*stream.Event, error) {
+	var data []byte
+	var err error
+	if kind == chirpEventDeleted {
+		data, err = json.Marshal(struct{
+			ID		uuid.UUID	`json:"id"`
+			UserID	uuid.UUID	`json:"user_id"`
+		}{ID: chirp.ID, UserID: chirp.UserID})
+	} else {
+		data, err = json.Marshal(mapChirp(chirp))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := db.CreateChirpEvent(ctx, database.CreateChirpEventParams{
+		Kind:		kind,
+		ChirpID:	chirp.ID,
+		UserID:		chirp.UserID,
+		Data:		data,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := mapChirpEvent(row)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Notify(ctx, database.NotifyParams{Channel: chirpEventsChannel, Payload: string(payload)})
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func mapChirpEvent(e database.ChirpEvent) stream.Event {
+	return stream.Event{
+		ID:			e.ID,
+		Kind:		e.Kind,
+		AuthorID:	e.UserID,
+		Data:		e.Data,
+	}
+}
+
+// handlerStreamChirps pushes chirps as they're created and deleted over
+// Server-Sent Events. Clients reconnecting with Last-Event-ID first get
+// the events they missed.
+func (cfg *apiConfig) handlerStreamChirps(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		respondWithError(rw, 500, "Streaming is not supported")
+		return
+	}
+
+	authorID := uuid.NullUUID{}
+	s := req.URL.Query().Get("author_id")
+	if s != "" {
+		parsedUUID, err := uuid.Parse(s)
+		if err != nil {
+			log.Printf("The ID of the user can't be parsed into a UUID")
+			respondWithError(rw, 400, "Invalid user ID")
+			return
+		}
+		authorID = uuid.NullUUID{UUID: parsedUUID, Valid: true}
+	}
+
+	var lastEventID int64
+	s = req.Header.Get("Last-Event-ID")
+	if s != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || lastEventID < 0 {
+			respondWithError(rw, 400, "Invalid Last-Event-ID")
+			return
+		}
+	}
+
+	// subscribing before looking up the missed events means nothing falls
+	// in between, events that show up in both are only sent once
+	sub := cfg.hub.Subscribe(authorID)
+	defer sub.Close()
+
+	var missed []database.ChirpEvent
+	if lastEventID > 0 {
+		var err error
+		missed, err = cfg.db.ListChirpEventsAfter(req.Context(), database.ListChirpEventsAfterParams{
+			AfterID:	lastEventID,
+			AuthorID:	authorID,
+			RowLimit:	maxReplayEvents,
+		})
+		if err != nil {
+			log.Printf("Error retrieving the missed chirp events: %s", err)
+			respondWithError(rw, 500, "Can't resume stream")
+			return
+		}
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(200)
+
+	replayed := map[int64]bool{}
+	for _, e := range missed {
+		if writeEvent(rw, mapChirpEvent(e)) != nil {
+			return
+		}
+		replayed[e.ID] = true
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			// the hub drops subscribers that fall behind, the client
+			// reconnects and resumes from the last event it got
+			if !ok {
+				return
+			}
+			if replayed[e.ID] {
+				continue
+			}
+			if writeEvent(rw, e) != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(rw, ": ping\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(rw http.ResponseWriter, e stream.Event) error {
+	_, err := fmt.Fprintf(rw, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Kind, e.Data)
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,396 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+const (
+	moderationActionLiftSuspension	= "lift_suspension"
+	moderationActionShadowBan		= "shadow_ban"
+	moderationActionLiftShadowBan	= "lift_shadow_ban"
+
+	maxSuspensionReasonLength	= 1000
+)
+
+type Suspension struct {
+	ID			uuid.UUID		`json:"id"`
+	CreatedAt	time.Time		`json:"created_at"`
+	UserID		uuid.UUID		`json:"user_id"`
+	ModeratorID	uuid.NullUUID	`json:"moderator_id"`
+	Reason		string			`json:"reason"`
+	ExpiresAt	time.Time		`json:"expires_at"`
+	LiftedAt	*time.Time		`json:"lifted_at,omitempty"`
+	Active		bool			`json:"active"`
+}
+
+func mapSuspension(s database.Suspension) Suspension {
+	mapped := Suspension{
+		ID:				s.ID,
+		CreatedAt:		s.CreatedAt,
+		UserID:			s.UserID,
+		ModeratorID:	s.ModeratorID,
+		Reason:			s.Reason,
+		ExpiresAt:		s.ExpiresAt,
+		Active:			!s.LiftedAt.Valid && s.ExpiresAt.After(time.Now().UTC()),
+	}
+	if s.LiftedAt.Valid {
+		mapped.LiftedAt = &s.LiftedAt.Time
+	}
+	return mapped
+}
+
+// ModeratedUser is a user as moderators see them.
+type ModeratedUser struct {
+	User
+	ShadowBannedAt	*time.Time	`json:"shadow_banned_at"`
+}
+
+func mapModeratedUser(user database.User) ModeratedUser {
+	mapped := ModeratedUser{
+		User: User{
+			ID:				user.ID,
+			CreatedAt:		user.CreatedAt,
+			UpdatedAt:		user.UpdatedAt,
+			Email:			user.Email,
+			IsChirpyRed:	user.IsChirpyRed,
+			Handle:			user.Handle.String,
+			Role:			user.Role,
+		},
+	}
+	if user.ShadowBannedAt.Valid {
+		mapped.ShadowBannedAt = &user.ShadowBannedAt.Time
+	}
+	return mapped
+}
+
+// parseSuspension reads a suspension length like "72h".
+func parseSuspension(duration string) (time.Duration, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil || d <= 0 || d > maxSuspension {
+		return 0, errors.New("Invalid duration, must be like 72h and at most a year")
+	}
+	return d, nil
+}
+
+// suspendUser suspends a user and signs them out everywhere by revoking
+// their refresh tokens. The JWTs they still hold are turned down by
+// middlewareBlockSuspended until they expire.
+func suspendUser(ctx context.Context, db *database.Queries, userID, moderatorID uuid.UUID, reason string, duration time.Duration) (database.Suspension, error) {
+	suspension, err := db.CreateSuspension(ctx, database.CreateSuspensionParams{
+		UserID:			userID,
+		ModeratorID:	uuid.NullUUID{UUID: moderatorID, Valid: true},
+		Reason:			reason,
+		ExpiresAt:		time.Now().UTC().Add(duration),
+	})
+	if err != nil {
+		return database.Suspension{}, err
+	}
+	err = db.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return database.Suspension{}, err
+	}
+	return suspension, nil
+}
+
+// respondIfSuspended turns the request down when the user is suspended,
+// telling them why and until when.
+func (cfg *apiConfig) respondIfSuspended(rw http.ResponseWriter, ctx context.Context, userID uuid.UUID) bool {
+	suspension, err := cfg.db.GetActiveSuspension(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error retrieving the suspensions of the user: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return true
+	}
+	respondWithError(rw, 403, fmt.Sprintf("Account is suspended until %s: %s", suspension.ExpiresAt.Format(time.RFC3339), suspension.Reason))
+	return true
+}
+
+// middlewareBlockSuspended turns down every write made with the JWT of a
+// suspended user. Reads and requests without a valid JWT go through, the
+// handlers answer those as usual.
+func (cfg *apiConfig) middlewareBlockSuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		token, err := auth.GetBearerToken(req.Header)
+		if err != nil {
+			next.ServeHTTP(rw, req)
+			return
+		}
+		userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+		if err != nil {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		if cfg.respondIfSuspended(rw, req.Context(), userId) {
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// canModerate applies the rules on who a moderator can act on: not on
+// themselves, and only admins can act on other moderators and admins.
+// It responds and reports false when the action isn't allowed.
+func canModerate(rw http.ResponseWriter, moderator, target database.User) bool {
+	if target.ID == moderator.ID {
+		respondWithError(rw, 400, "Moderators can't act on themselves")
+		return false
+	}
+	if auth.HasRole(target.Role, auth.RoleModerator) && !auth.HasRole(moderator.Role, auth.RoleAdmin) {
+		respondWithError(rw, 403, "Only admins can act on moderators")
+		return false
+	}
+	return true
+}
+
+// moderationTarget identifies the moderator and the user they're acting
+// on, as long as canModerate allows it.
+func (cfg *apiConfig) moderationTarget(rw http.ResponseWriter, req *http.Request) (uuid.UUID, database.User, bool) {
+	moderator, ok := staffFromContext(req.Context())
+	if !ok {
+		respondWithError(rw, 403, "Not allowed to access this resource")
+		return uuid.Nil, database.User{}, false
+	}
+
+	targetID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return uuid.Nil, database.User{}, false
+	}
+
+	user, err := cfg.db.GetUserByID(req.Context(), targetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "User not found")
+		return uuid.Nil, database.User{}, false
+	}
+	if err != nil {
+		log.Printf("Error retrieving the user from the database: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return uuid.Nil, database.User{}, false
+	}
+
+	if !canModerate(rw, moderator, user) {
+		return uuid.Nil, database.User{}, false
+	}
+	return moderator.ID, user, true
+}
+
+// handlerSuspendUser suspends a user for a while, e.g.
+// {"reason": "spam", "duration": "72h"}. Suspended users can't log in,
+// refresh their JWT or change anything until it expires or is lifted.
+func (cfg *apiConfig) handlerSuspendUser(rw http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Reason		string	`json:"reason"`
+		Duration	string	`json:"duration"`
+	}
+
+	moderatorId, user, ok := cfg.moderationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	params := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %s", err)
+		respondWithError(rw, 500, "Something went wrong")
+		return
+	}
+
+	params.Reason = strings.TrimSpace(params.Reason)
+	if params.Reason == "" {
+		respondWithError(rw, 400, "Missing reason for the suspension")
+		return
+	}
+	if len(params.Reason) > maxSuspensionReasonLength {
+		respondWithError(rw, 400, fmt.Sprintf("Reason can be at most %d characters long", maxSuspensionReasonLength))
+		return
+	}
+	duration, err := parseSuspension(params.Duration)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't suspend user")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	suspension, err := suspendUser(req.Context(), qtx, user.ID, moderatorId, params.Reason, duration)
+	if err == nil {
+		_, err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+			ModeratorID:	uuid.NullUUID{UUID: moderatorId, Valid: true},
+			Action:			moderationActionSuspendUser,
+			UserID:			uuid.NullUUID{UUID: user.ID, Valid: true},
+			Note:			params.Reason,
+		})
+	}
+	if err != nil {
+		log.Printf("Error suspending the user on the database: %s", err)
+		respondWithError(rw, 500, "Can't suspend user")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the suspension: %s", err)
+		respondWithError(rw, 500, "Can't suspend user")
+		return
+	}
+
+	respondWithJSON(rw, 201, mapSuspension(suspension))
+}
+
+// handlerGetUserSuspensions lists every suspension of a user, newest first.
+func (cfg *apiConfig) handlerGetUserSuspensions(rw http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		log.Printf("The ID of the user can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid user ID")
+		return
+	}
+
+	suspensions, err := cfg.db.ListUserSuspensions(req.Context(), userID)
+	if err != nil {
+		log.Printf("Error retrieving the suspensions from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve suspensions")
+		return
+	}
+
+	mapped := []Suspension{}
+	for _, s := range suspensions {
+		mapped = append(mapped, mapSuspension(s))
+	}
+	respondWithJSON(rw, 200, mapped)
+}
+
+// handlerLiftSuspension ends a user's suspension early. Their refresh
+// tokens stay revoked, they log in again.
+func (cfg *apiConfig) handlerLiftSuspension(rw http.ResponseWriter, req *http.Request) {
+	moderatorId, user, ok := cfg.moderationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't lift suspension")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	lifted, err := qtx.LiftSuspensions(req.Context(), user.ID)
+	if err != nil {
+		log.Printf("Error lifting the suspension on the database: %s", err)
+		respondWithError(rw, 500, "Can't lift suspension")
+		return
+	}
+	if len(lifted) == 0 {
+		respondWithError(rw, 404, "User isn't suspended")
+		return
+	}
+
+	_, err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID:	uuid.NullUUID{UUID: moderatorId, Valid: true},
+		Action:			moderationActionLiftSuspension,
+		UserID:			uuid.NullUUID{UUID: user.ID, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error recording the moderation action: %s", err)
+		respondWithError(rw, 500, "Can't lift suspension")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the lifted suspension: %s", err)
+		respondWithError(rw, 500, "Can't lift suspension")
+		return
+	}
+
+	rw.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerShadowBanUser(rw http.ResponseWriter, req *http.Request) {
+	cfg.setShadowBan(rw, req, true)
+}
+
+func (cfg *apiConfig) handlerLiftShadowBan(rw http.ResponseWriter, req *http.Request) {
+	cfg.setShadowBan(rw, req, false)
+}
+
+// setShadowBan shadow-bans a user or lifts it. Shadow-banned users carry
+// on as usual, but nobody else gets to see their chirps or hear from them.
+func (cfg *apiConfig) setShadowBan(rw http.ResponseWriter, req *http.Request, banned bool) {
+	moderatorId, user, ok := cfg.moderationTarget(rw, req)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting the transaction: %s", err)
+		respondWithError(rw, 500, "Can't update shadow ban")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	action := moderationActionShadowBan
+	if banned {
+		user, err = qtx.ShadowBanUser(req.Context(), user.ID)
+	} else {
+		action = moderationActionLiftShadowBan
+		user, err = qtx.LiftShadowBan(req.Context(), user.ID)
+	}
+	if err == nil {
+		_, err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+			ModeratorID:	uuid.NullUUID{UUID: moderatorId, Valid: true},
+			Action:			action,
+			UserID:			uuid.NullUUID{UUID: user.ID, Valid: true},
+		})
+	}
+	if err != nil {
+		log.Printf("Error updating the shadow ban on the database: %s", err)
+		respondWithError(rw, 500, "Can't update shadow ban")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error committing the shadow ban: %s", err)
+		respondWithError(rw, 500, "Can't update shadow ban")
+		return
+	}
+
+	respondWithJSON(rw, 200, mapModeratedUser(user))
+}
\ No newline at end of file
@@ -0,0 +1,98 @@
+package main
+
+import(
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+type ThreadNode struct {
+	Chirp
+	Replies	[]*ThreadNode	`json:"replies"`
+}
+
+// handlerGetThread returns the whole conversation a chirp belongs to as a
+// tree rooted at the chirp that started it, replies ordered oldest first.
+func (cfg *apiConfig) handlerGetThread(rw http.ResponseWriter, req *http.Request) {
+	viewer, err := cfg.viewerFromRequest(req)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	cid := req.PathValue("chirpID")
+	if cid == "" {
+		respondWithError(rw, 400, "Missing chirp ID in request")
+		return
+	}
+
+	parsedUUID, err := uuid.Parse(cid)
+	if err != nil {
+		log.Printf("The ID of the request can't be parsed into a UUID")
+		respondWithError(rw, 400, "Invalid ID")
+		return
+	}
+
+	chirp, err := cfg.visibleChirp(req.Context(), viewer, parsedUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(rw, 404, "Chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving the chirp from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve thread")
+		return
+	}
+
+	rootID := mapChirp(chirp).ThreadID
+	chirps, err := cfg.db.ListThread(req.Context(), database.ListThreadParams{RootID: rootID, ViewerID: viewer})
+	if err != nil {
+		log.Printf("Error retrieving the thread from the database: %s", err)
+		respondWithError(rw, 500, "Can't retrieve thread")
+		return
+	}
+
+	mappedChirps, err := cfg.mapChirps(req.Context(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error retrieving the details of the thread: %s", err)
+		respondWithError(rw, 500, "Can't retrieve thread")
+		return
+	}
+
+	respondWithJSON(rw, 200, buildThread(rootID, mappedChirps))
+}
+
+// buildThread links chirps, sorted oldest first, to their parents. Replies
+// whose parent has been deleted hang off the root so they aren't lost. A
+// root the viewer can't see, e.g. because of a block, is a placeholder
+// just like a deleted one, without saying who wrote it.
+func buildThread(rootID uuid.UUID, chirps []Chirp) *ThreadNode {
+	nodes := map[uuid.UUID]*ThreadNode{}
+	for _, c := range chirps {
+		nodes[c.ID] = &ThreadNode{Chirp: c, Replies: []*ThreadNode{}}
+	}
+
+	root, ok := nodes[rootID]
+	if !ok {
+		root = &ThreadNode{
+			Chirp:		Chirp{ID: rootID, ThreadID: rootID, Kind: chirpKindChirp, Deleted: true},
+			Replies:	[]*ThreadNode{},
+		}
+	}
+	for _, c := range chirps {
+		if c.ID == rootID {
+			continue
+		}
+		parent, ok := nodes[c.InReplyTo.UUID]
+		if !c.InReplyTo.Valid || !ok {
+			parent = root
+		}
+		parent.Replies = append(parent.Replies, nodes[c.ID])
+	}
+	return root
+}
\ No newline at end of file
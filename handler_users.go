@@ -1,13 +1,17 @@
 package main
 
 import(
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/chirptext"
 	"github.com/neriAle/chirpy/internal/database"
 )
 
@@ -15,10 +19,12 @@ func (cfg *apiConfig) handlerCreateUser(rw http.ResponseWriter, req *http.Reques
 	type parameters struct {
 		Email 		string `json:"email"`
 		Password 	string `json:"password"`
+		Handle		string `json:"handle"`
 	}
 	type userParameters struct {
 		Email          string
 		HashedPassword string
+		Handle         sql.NullString
 	}
 	params := parameters{}
 
@@ -30,6 +36,12 @@ func (cfg *apiConfig) handlerCreateUser(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	handle, err := parseHandle(params.Handle)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
 	hash, err := auth.HashPassword(params.Password)
 	if err != nil {
 		log.Printf("Error hashing the password: %s", err)
@@ -37,15 +49,26 @@ func (cfg *apiConfig) handlerCreateUser(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	userParams := userParameters{Email: params.Email, HashedPassword: hash}
+	userParams := userParameters{Email: params.Email, HashedPassword: hash, Handle: handle}
 	user, err := cfg.db.CreateUser(req.Context(), database.CreateUserParams(userParams))
+	if isUniqueViolation(err, "users_handle_key") {
+		respondWithError(rw, 409, "Handle is already taken")
+		return
+	}
 	if err != nil {
 		log.Printf("Error creating the user on the database: %s", err)
 		respondWithError(rw, 500, "Can't create user")
 		return
 	}
 
-	mappedUser := User(user)
+	mappedUser := User{
+		ID:				user.ID,
+		CreatedAt:		user.CreatedAt,
+		UpdatedAt:		user.UpdatedAt,
+		Email:			user.Email,
+		IsChirpyRed:	user.IsChirpyRed,
+		Handle:			user.Handle.String,
+	}
 	respondWithJSON(rw, 201, mappedUser)
 }
 
@@ -67,10 +90,12 @@ func (cfg *apiConfig) handlerUpdateUser(rw http.ResponseWriter, req *http.Reques
 	type parameters struct {
 		Email 		string `json:"email"`
 		Password 	string `json:"password"`
+		Handle		string `json:"handle"`
 	}
 	type updateUserParameters struct {
 		Email          string
 		HashedPassword string
+		Handle         sql.NullString
 		ID             uuid.UUID
 	}
 	params := parameters{}
@@ -83,6 +108,13 @@ func (cfg *apiConfig) handlerUpdateUser(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	// an omitted handle keeps the current one
+	handle, err := parseHandle(params.Handle)
+	if err != nil {
+		respondWithError(rw, 400, err.Error())
+		return
+	}
+
 	hash, err := auth.HashPassword(params.Password)
 	if err != nil {
 		log.Printf("Error hashing the password: %s", err)
@@ -90,15 +122,26 @@ func (cfg *apiConfig) handlerUpdateUser(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	updateParams := updateUserParameters{Email: params.Email, HashedPassword: hash, ID: userId}
+	updateParams := updateUserParameters{Email: params.Email, HashedPassword: hash, Handle: handle, ID: userId}
 	user, err := cfg.db.UpdateUser(req.Context(), database.UpdateUserParams(updateParams))
+	if isUniqueViolation(err, "users_handle_key") {
+		respondWithError(rw, 409, "Handle is already taken")
+		return
+	}
 	if err != nil {
 		log.Printf("Error updating the user on the database: %s", err)
 		respondWithError(rw, 500, "Can't update user")
 		return
 	}
 
-	mappedUser := User(user)
+	mappedUser := User{
+		ID:				user.ID,
+		CreatedAt:		user.CreatedAt,
+		UpdatedAt:		user.UpdatedAt,
+		Email:			user.Email,
+		IsChirpyRed:	user.IsChirpyRed,
+		Handle:			user.Handle.String,
+	}
 	respondWithJSON(rw, 200, mappedUser)
 }
 
@@ -131,6 +174,10 @@ func (cfg *apiConfig) handlerLoginUser(rw http.ResponseWriter, req *http.Request
 		return
 	}
 
+	if cfg.respondIfSuspended(rw, req.Context(), user.ID) {
+		return
+	}
+
 	expiration := time.Hour
 	token, err := auth.MakeJWT(user.ID, cfg.tokenSecret, expiration)
 	if err != nil {
@@ -176,6 +223,8 @@ func (cfg *apiConfig) handlerLoginUser(rw http.ResponseWriter, req *http.Request
 			UpdatedAt: 		user.UpdatedAt, 
 			Email: 			user.Email,
 			IsChirpyRed:	user.IsChirpyRed,
+			Handle:			user.Handle.String,
+			Role:			user.Role,
 		}, 
 		Token: token,
 		Refresh_token: refresh_token,
@@ -198,6 +247,10 @@ func (cfg *apiConfig) handlerRefreshJWT(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	if cfg.respondIfSuspended(rw, req.Context(), user) {
+		return
+	}
+
 	expiration := time.Hour
 	token, err := auth.MakeJWT(user, cfg.tokenSecret, expiration)
 	if err != nil {
@@ -280,4 +333,22 @@ func (cfg *apiConfig) handlerUpgradeUser(rw http.ResponseWriter, req *http.Reque
 	}
 
 	rw.WriteHeader(204)
+}
+
+// parseHandle validates an optional handle from a request body, an empty
+// handle comes back as NULL.
+func parseHandle(handle string) (sql.NullString, error) {
+	if handle == "" {
+		return sql.NullString{}, nil
+	}
+	handle = chirptext.NormalizeHandle(handle)
+	if !chirptext.ValidHandle(handle) {
+		return sql.NullString{}, errors.New("Handle must be 3 to 15 letters, digits or underscores")
+	}
+	return sql.NullString{String: handle, Valid: true}, nil
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
 }
\ No newline at end of file
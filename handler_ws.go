@@ -0,0 +1,294 @@
+package main
+
+import(
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+	"github.com/neriAle/chirpy/internal/chirptext"
+	"github.com/neriAle/chirpy/internal/stream"
+	"github.com/neriAle/chirpy/internal/websocket"
+)
+
+const (
+	wsPingInterval		= 30 * time.Second
+	// a client that stays silent for longer, pongs included, is gone
+	wsReadTimeout		= 60 * time.Second
+	wsWriteTimeout		= 10 * time.Second
+	// authors and hashtags a single connection can follow
+	maxWSSubscriptions	= 100
+)
+
+const (
+	wsChannelTimeline		= "timeline"
+	wsChannelAuthor			= "author"
+	wsChannelHashtag		= "hashtag"
+	wsChannelNotifications	= "notifications"
+)
+
+// wsClientMessage is what clients send: subscribe, unsubscribe or ping.
+type wsClientMessage struct {
+	Type	string `json:"type"`
+	Channel	string `json:"channel"`
+	ID		string `json:"id"`
+	Tag		string `json:"tag"`
+}
+
+type wsServerMessage struct {
+	Type	string			`json:"type"`
+	Channel	string			`json:"channel,omitempty"`
+	ID		string			`json:"id,omitempty"`
+	Tag		string			`json:"tag,omitempty"`
+	Event	string			`json:"event,omitempty"`
+	EventID	int64			`json:"event_id,omitempty"`
+	Data	json.RawMessage	`json:"data,omitempty"`
+	Error	string			`json:"error,omitempty"`
+}
+
+// wsSubscriptions is what a single connection listens to. It's only ever
+// touched by the goroutine serving the connection.
+type wsSubscriptions struct {
+	userID			uuid.UUID
+	timeline		bool
+	following		map[uuid.UUID]bool
+	authors			map[uuid.UUID]bool
+	hashtags		map[string]bool
+	notifications	bool
+	hidden			map[uuid.UUID]bool
+}
+
+// handlerWebSocket serves live timelines and notifications over a
+// WebSocket. Browsers can't set headers on the handshake, so the JWT is
+// also accepted in the access_token query parameter.
+func (cfg *apiConfig) handlerWebSocket(rw http.ResponseWriter, req *http.Request) {
+	token := req.URL.Query().Get("access_token")
+	if token == "" {
+		var err error
+		token, err = auth.GetBearerToken(req.Header)
+		if err != nil {
+			log.Printf("Header is missing JWT: %s", err)
+			respondWithError(rw, 401, "Header is missing JWT")
+			return
+		}
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(rw, 401, "JWT is not valid")
+		return
+	}
+
+	subs := &wsSubscriptions{
+		userID:		userId,
+		following:	map[uuid.UUID]bool{},
+		authors:	map[uuid.UUID]bool{},
+		hashtags:	map[string]bool{},
+	}
+	// blocks and mutes are reloaded whenever they change
+	err = cfg.loadWSHidden(req.Context(), subs)
+	if err != nil {
+		log.Printf("Error retrieving the hidden users: %s", err)
+		respondWithError(rw, 500, "Can't open connection")
+		return
+	}
+
+	conn, err := websocket.Upgrade(rw, req)
+	if err != nil {
+		log.Printf("Error upgrading the connection: %s", err)
+		respondWithError(rw, 400, "Expected a WebSocket handshake")
+		return
+	}
+	defer conn.Close()
+	conn.SetReadTimeout(wsReadTimeout)
+
+	sub := cfg.hub.SubscribeUser(userId)
+	defer sub.Close()
+
+	// the reader only decodes, everything else happens on this goroutine
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messages := make(chan wsClientMessage)
+	go func() {
+		defer close(messages)
+		for {
+			opcode, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg := wsClientMessage{}
+			if opcode != websocket.TextMessage || json.Unmarshal(data, &msg) != nil {
+				msg = wsClientMessage{Type: "invalid"}
+			}
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			reply := cfg.handleWSMessage(ctx, subs, msg)
+			if writeWSMessage(conn, reply) != nil {
+				return
+			}
+		case e, ok := <-sub.C:
+			// the hub gave up on us for falling behind, the client is
+			// better off reconnecting and reloading what it missed
+			if !ok {
+				conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+				conn.WriteClose(websocket.CloseTryAgainLater, "Too slow to keep up")
+				return
+			}
+			if e.Kind == relationsChangedEvent {
+				err := cfg.loadWSHidden(ctx, subs)
+				if err != nil {
+					log.Printf("Error reloading the hidden users: %s", err)
+				}
+				continue
+			}
+			if !subs.matches(e) {
+				continue
+			}
+			msg := wsServerMessage{Type: "event", Event: e.Kind, EventID: e.ID, Data: e.Data}
+			if writeWSMessage(conn, msg) != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if conn.WriteMessage(websocket.PingMessage, nil) != nil {
+				return
+			}
+		}
+	}
+}
+
+// loadWSHidden replaces the users hidden from a connection with the ones
+// blocked by, blocking or muted by its user.
+func (cfg *apiConfig) loadWSHidden(ctx context.Context, subs *wsSubscriptions) error {
+	hidden, err := cfg.db.ListHiddenUserIDs(ctx, subs.userID)
+	if err != nil {
+		return err
+	}
+	subs.hidden = map[uuid.UUID]bool{}
+	for _, id := range hidden {
+		subs.hidden[id] = true
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleWSMessage(ctx context.Context, subs *wsSubscriptions, msg wsClientMessage) wsServerMessage {
+	reply := wsServerMessage{Channel: msg.Channel, ID: msg.ID, Tag: msg.Tag}
+	switch msg.Type {
+	case "ping":
+		return wsServerMessage{Type: "pong"}
+	case "subscribe", "unsubscribe":
+	default:
+		return wsServerMessage{Type: "error", Error: "Unknown message type"}
+	}
+	subscribe := msg.Type == "subscribe"
+
+	switch msg.Channel {
+	case wsChannelTimeline:
+		subs.timeline = subscribe
+		subs.following = map[uuid.UUID]bool{}
+		if subscribe {
+			// follows are taken as they are when subscribing
+			following, err := cfg.db.ListFollowingIDs(ctx, subs.userID)
+			if err != nil {
+				log.Printf("Error retrieving the followed users: %s", err)
+				return wsServerMessage{Type: "error", Channel: msg.Channel, Error: "Can't subscribe to timeline"}
+			}
+			for _, id := range following {
+				subs.following[id] = true
+			}
+			subs.following[subs.userID] = true
+		}
+	case wsChannelAuthor:
+		authorID, err := uuid.Parse(msg.ID)
+		if err != nil {
+			return wsServerMessage{Type: "error", Channel: msg.Channel, Error: "Invalid user ID"}
+		}
+		if subscribe && len(subs.authors) + len(subs.hashtags) >= maxWSSubscriptions {
+			return wsServerMessage{Type: "error", Channel: msg.Channel, Error: "Too many subscriptions"}
+		}
+		if subscribe {
+			subs.authors[authorID] = true
+		} else {
+			delete(subs.authors, authorID)
+		}
+	case wsChannelHashtag:
+		tag := chirptext.NormalizeTag(msg.Tag)
+		if tag == "" {
+			return wsServerMessage{Type: "error", Channel: msg.Channel, Error: "Missing hashtag"}
+		}
+		if subscribe && len(subs.authors) + len(subs.hashtags) >= maxWSSubscriptions {
+			return wsServerMessage{Type: "error", Channel: msg.Channel, Error: "Too many subscriptions"}
+		}
+		if subscribe {
+			subs.hashtags[tag] = true
+		} else {
+			delete(subs.hashtags, tag)
+		}
+		reply.Tag = tag
+	case wsChannelNotifications:
+		subs.notifications = subscribe
+	default:
+		return wsServerMessage{Type: "error", Channel: msg.Channel, Error: "Unknown channel"}
+	}
+
+	reply.Type = msg.Type + "d"
+	return reply
+}
+
+// matches decides whether an event from the hub goes out on this
+// connection. Deletions can't be matched to hashtags since their body is
+// gone, they only reach timeline and author subscriptions.
+func (subs *wsSubscriptions) matches(e stream.Event) bool {
+	// the hub only hands out the private events of this connection's user
+	if e.Recipient.Valid {
+		return subs.notifications
+	}
+	if subs.hidden[e.AuthorID] {
+		return false
+	}
+	if (subs.timeline && subs.following[e.AuthorID]) || subs.authors[e.AuthorID] {
+		return true
+	}
+	if len(subs.hashtags) == 0 || e.Kind != chirpEventCreated {
+		return false
+	}
+
+	chirp := Chirp{}
+	if json.Unmarshal(e.Data, &chirp) != nil {
+		return false
+	}
+	for _, tag := range chirptext.Hashtags(chirp.Body) {
+		if subs.hashtags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+func writeWSMessage(conn *websocket.Conn, msg wsServerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
\ No newline at end of file
@@ -12,6 +12,30 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	RoleUser		= "user"
+	RoleModerator	= "moderator"
+	RoleAdmin		= "admin"
+)
+
+// roleRanks orders the roles, each role can do everything the ones below
+// it can.
+var roleRanks = map[string]int{
+	RoleUser:		1,
+	RoleModerator:	2,
+	RoleAdmin:		3,
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// HasRole reports whether role grants at least the access of required.
+func HasRole(role, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
 func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
 	claims := jwt.RegisteredClaims{
 		Issuer:		"chirpy",
@@ -69,6 +69,26 @@ func TestJWTWrongSecret(t *testing.T) {
 	}
 }
 
+func TestHasRole(t *testing.T) {
+	cases := []struct{
+		role		string
+		required	string
+		expected	bool
+	}{
+		{RoleUser, RoleUser, true},
+		{RoleUser, RoleModerator, false},
+		{RoleModerator, RoleModerator, true},
+		{RoleModerator, RoleAdmin, false},
+		{RoleAdmin, RoleModerator, true},
+		{"root", RoleUser, false},
+	}
+	for _, c := range cases {
+		if HasRole(c.role, c.required) != c.expected {
+			t.Errorf("HasRole(%s, %s) should be %v", c.role, c.required, c.expected)
+		}
+	}
+}
+
 func TestGetBearerToken(t *testing.T) {
 	headers := http.Header{}
 	headers.Add("Authorization", "Bearer test_token")
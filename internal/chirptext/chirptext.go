@@ -0,0 +1,69 @@
+package chirptext
+
+import(
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const maxTagLength = 50
+const minHandleLength = 3
+const maxHandleLength = 15
+
+// a tag must start the body or follow something that can't be part of a word,
+// so emails and URL fragments like "a#b" aren't picked up
+var hashtagRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])#([\p{L}\p{N}_]+)`)
+var mentionRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([A-Za-z0-9_]+)`)
+var handleRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Hashtags returns the distinct tags in a chirp body, lowercased and without
+// the leading '#', in the order they first appear.
+func Hashtags(body string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, m := range hashtagRegex.FindAllStringSubmatch(body, -1) {
+		tag := strings.ToLower(m[1])
+		if len([]rune(tag)) > maxTagLength || !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeTag turns user input like "#GoLang" into the stored form of a tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// Mentions returns the distinct, normalized handles mentioned in a chirp
+// body. Anything that couldn't be a valid handle is ignored.
+func Mentions(body string) []string {
+	handles := []string{}
+	seen := map[string]bool{}
+	for _, m := range mentionRegex.FindAllStringSubmatch(body, -1) {
+		handle := NormalizeHandle(m[1])
+		if !ValidHandle(handle) || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
+
+// NormalizeHandle lowercases a handle and drops a leading '@', handles are
+// unique regardless of case.
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ValidHandle reports whether a normalized handle is 3 to 15 characters of
+// lowercase letters, digits and underscores.
+func ValidHandle(handle string) bool {
+	return len(handle) >= minHandleLength && len(handle) <= maxHandleLength && handleRegex.MatchString(handle)
+}
\ No newline at end of file
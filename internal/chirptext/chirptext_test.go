@@ -0,0 +1,67 @@
+package chirptext
+
+import(
+	"slices"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	cases := []struct{
+		body	string
+		tags	[]string
+	}{
+		{"no tags here", []string{}},
+		{"#golang is fun", []string{"golang"}},
+		{"I love #Go and #go and #GO", []string{"go"}},
+		{"(#first),#second. #third!", []string{"first", "second", "third"}},
+		{"mail me at me#work or see example.com/#anchor", []string{}},
+		{"we're #1 but #team1 rocks", []string{"team1"}},
+		{"#café au lait", []string{"café"}},
+	}
+	for _, c := range cases {
+		tags := Hashtags(c.body)
+		if !slices.Equal(tags, c.tags) {
+			t.Errorf("Hashtags(%q) = %v, expected %v", c.body, tags, c.tags)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	if tag := NormalizeTag(" #GoLang "); tag != "golang" {
+		t.Errorf("Expected golang, got %s", tag)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	cases := []struct{
+		body		string
+		handles		[]string
+	}{
+		{"hello world", []string{}},
+		{"@alice hi", []string{"alice"}},
+		{"cc @Bob, @bob and @carol_99!", []string{"bob", "carol_99"}},
+		{"write to bob@example.com", []string{}},
+		{"@al is too short, @averyveryverylonghandle too long", []string{}},
+	}
+	for _, c := range cases {
+		handles := Mentions(c.body)
+		if !slices.Equal(handles, c.handles) {
+			t.Errorf("Mentions(%q) = %v, expected %v", c.body, handles, c.handles)
+		}
+	}
+}
+
+func TestValidHandle(t *testing.T) {
+	valid := []string{"bob", "carol_99", "abcdefghijklmno"}
+	invalid := []string{"", "al", "Bob", "with space", "dash-ed", "abcdefghijklmnop"}
+	for _, h := range valid {
+		if !ValidHandle(h) {
+			t.Errorf("Expected %q to be a valid handle", h)
+		}
+	}
+	for _, h := range invalid {
+		if ValidHandle(h) {
+			t.Errorf("Expected %q to be an invalid handle", h)
+		}
+	}
+}
\ No newline at end of file
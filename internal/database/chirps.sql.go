@@ -7,29 +7,94 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countReplies = `-- name: CountReplies :many
+SELECT in_reply_to, COUNT(*) AS replies FROM chirps
+WHERE in_reply_to = ANY($1::uuid[])
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $2
+)
+GROUP BY in_reply_to
+`
+
+type CountRepliesParams struct {
+	ChirpIds []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+type CountRepliesRow struct {
+	InReplyTo uuid.NullUUID
+	Replies   int64
+}
+
+func (q *Queries) CountReplies(ctx context.Context, arg CountRepliesParams) ([]CountRepliesRow, error) {
+	rows, err := q.db.QueryContext(ctx, countReplies, pq.Array(arg.ChirpIds), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRepliesRow
+	for rows.Next() {
+		var i CountRepliesRow
+		if err := rows.Scan(
+			&i.InReplyTo,
+			&i.Replies,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, thread_id, kind, original_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    (SELECT COALESCE(parent.thread_id, parent.id) FROM chirps AS parent WHERE parent.id = $3),
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body       string
+	UserID     uuid.UUID
+	InReplyTo  uuid.NullUUID
+	Kind       string
+	OriginalID uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.InReplyTo,
+		arg.Kind,
+		arg.OriginalID,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,27 +102,54 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
-DELETE FROM chirps
+const getChirp = `-- name: GetChirp :one
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
 WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
-	return err
+func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
+	)
+	return i, err
 }
 
-const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE id = $1 LIMIT 1
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
 `
 
-func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirp, id)
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -65,13 +157,21 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
 const getChirpsByAuthor = `-- name: GetChirpsByAuthor :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
 WHERE user_id = $1
+AND deleted_at IS NULL
 ORDER BY created_at
 `
 
@@ -90,6 +190,13 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.BodyTsv,
+			&i.InReplyTo,
+			&i.ThreadID,
+			&i.Kind,
+			&i.OriginalID,
+			&i.DeletedAt,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -104,8 +211,160 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 	return items, nil
 }
 
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE id = ANY($1::uuid[])
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $2
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+)
+`
+
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.InReplyTo,
+			&i.ThreadID,
+			&i.Kind,
+			&i.OriginalID,
+			&i.DeletedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirp = `-- name: GetDeletedChirp :one
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE id = $1
+AND deleted_at IS NOT NULL
+LIMIT 1
+`
+
+func (q *Queries) GetDeletedChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getVisibleChirp = `-- name: GetVisibleChirp :one
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE id = $1
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $2
+)
+LIMIT 1
+`
+
+type GetVisibleChirpParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetVisibleChirp(ctx context.Context, arg GetVisibleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getVisibleChirp, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const hideChirp = `-- name: HideChirp :one
+UPDATE chirps
+    SET deleted_at = COALESCE(deleted_at, NOW()),
+    hidden_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, hideChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
 const listChirps = `-- name: ListChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
 ORDER BY created_at
 `
 
@@ -124,6 +383,377 @@ func (q *Queries) ListChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.BodyTsv,
+			&i.InReplyTo,
+			&i.ThreadID,
+			&i.Kind,
+			&i.OriginalID,
+			&i.DeletedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsAfter = `-- name: ListChirpsAfter :many
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $1
+)
+AND ($2::uuid IS NULL OR user_id = $2)
+AND ($3::timestamp IS NULL OR created_at >= $3)
+AND ($4::timestamp IS NULL OR created_at < $4)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $1 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $1)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $1 AND mutes.muted_id = chirps.user_id
+)
+AND ($5::timestamp IS NULL
+    OR (created_at, id) > ($5, $6::uuid))
+ORDER BY created_at, id
+LIMIT $7
+`
+
+type ListChirpsAfterParams struct {
+	AuthorID       uuid.NullUUID
+	Since          sql.NullTime
+	Until          sql.NullTime
+	ViewerID       uuid.NullUUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	RowLimit       int32
+}
+
+func (q *Queries) ListChirpsAfter(ctx context.Context, arg ListChirpsAfterParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsAfter,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.ViewerID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.InReplyTo,
+			&i.ThreadID,
+			&i.Kind,
+			&i.OriginalID,
+			&i.DeletedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsBefore = `-- name: ListChirpsBefore :many
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $1
+)
+AND ($2::uuid IS NULL OR user_id = $2)
+AND ($3::timestamp IS NULL OR created_at >= $3)
+AND ($4::timestamp IS NULL OR created_at < $4)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $1 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $1)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $1 AND mutes.muted_id = chirps.user_id
+)
+AND ($5::timestamp IS NULL
+    OR (created_at, id) < ($5, $6::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $7
+`
+
+type ListChirpsBeforeParams struct {
+	AuthorID        uuid.NullUUID
+	Since           sql.NullTime
+	Until           sql.NullTime
+	ViewerID        uuid.NullUUID
+	BeforeCreatedAt sql.NullTime
+	BeforeID        uuid.NullUUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListChirpsBefore(ctx context.Context, arg ListChirpsBeforeParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsBefore,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.ViewerID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.InReplyTo,
+			&i.ThreadID,
+			&i.Kind,
+			&i.OriginalID,
+			&i.DeletedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listThread = `-- name: ListThread :many
+SELECT id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at FROM chirps
+WHERE (id = $1 OR thread_id = $1)
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $2
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+)
+-- like a single chirp, the root shows up even when its author is muted
+AND (id = $1 OR NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+))
+ORDER BY created_at, id
+`
+
+type ListThreadParams struct {
+	RootID   uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) ListThread(ctx context.Context, arg ListThreadParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listThread, arg.RootID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.InReplyTo,
+			&i.ThreadID,
+			&i.Kind,
+			&i.OriginalID,
+			&i.DeletedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < $1::timestamp
+AND hidden_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM chirps AS replies
+    WHERE replies.thread_id = chirps.id
+)
+-- open reports keep their chirp, so they can't collide once it's set to NULL
+AND NOT EXISTS (
+    SELECT 1 FROM reports
+    WHERE reports.chirp_id = chirps.id
+    AND reports.status = 'open'
+)
+`
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+    SET deleted_at = NULL
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at
+`
+
+func (q *Queries) RestoreChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const searchChirpsAfter = `-- name: SearchChirpsAfter :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.body_tsv, chirps.in_reply_to, chirps.thread_id, chirps.kind, chirps.original_id, chirps.deleted_at, chirps.hidden_at, ts_rank(chirps.body_tsv, websearch_to_tsquery('english', $1)) AS rank
+FROM chirps
+WHERE chirps.body_tsv @@ websearch_to_tsquery('english', $1)
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $2
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+)
+AND ($3::uuid IS NULL OR chirps.user_id = $3)
+AND ($4::real IS NULL
+    OR (ts_rank(chirps.body_tsv, websearch_to_tsquery('english', $1)), chirps.created_at, chirps.id)
+        < ($4, $5::timestamp, $6::uuid))
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $7
+`
+
+type SearchChirpsAfterParams struct {
+	Query          string
+	AuthorID       uuid.NullUUID
+	ViewerID       uuid.NullUUID
+	AfterRank      sql.NullFloat64
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	RowLimit       int32
+}
+
+type SearchChirpsAfterRow struct {
+	Chirp Chirp
+	Rank  float32
+}
+
+func (q *Queries) SearchChirpsAfter(ctx context.Context, arg SearchChirpsAfterParams) ([]SearchChirpsAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirpsAfter,
+		arg.Query,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.AfterRank,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsAfterRow
+	for rows.Next() {
+		var i SearchChirpsAfterRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.BodyTsv,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.ThreadID,
+			&i.Chirp.Kind,
+			&i.Chirp.OriginalID,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.HiddenAt,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -137,3 +767,134 @@ func (q *Queries) ListChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const searchChirpsBefore = `-- name: SearchChirpsBefore :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.body_tsv, chirps.in_reply_to, chirps.thread_id, chirps.kind, chirps.original_id, chirps.deleted_at, chirps.hidden_at, ts_rank(chirps.body_tsv, websearch_to_tsquery('english', $1)) AS rank
+FROM chirps
+WHERE chirps.body_tsv @@ websearch_to_tsquery('english', $1)
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM $2
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+)
+AND ($3::uuid IS NULL OR chirps.user_id = $3)
+AND (ts_rank(chirps.body_tsv, websearch_to_tsquery('english', $1)), chirps.created_at, chirps.id)
+    > ($4::real, $5::timestamp, $6::uuid)
+ORDER BY rank, chirps.created_at, chirps.id
+LIMIT $7
+`
+
+type SearchChirpsBeforeParams struct {
+	Query           string
+	AuthorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	BeforeRank      float32
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	RowLimit        int32
+}
+
+type SearchChirpsBeforeRow struct {
+	Chirp Chirp
+	Rank  float32
+}
+
+func (q *Queries) SearchChirpsBefore(ctx context.Context, arg SearchChirpsBeforeParams) ([]SearchChirpsBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirpsBefore,
+		arg.Query,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.BeforeRank,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsBeforeRow
+	for rows.Next() {
+		var i SearchChirpsBeforeRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.BodyTsv,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.ThreadID,
+			&i.Chirp.Kind,
+			&i.Chirp.OriginalID,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.HiddenAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+UPDATE chirps
+    SET deleted_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, id)
+	return err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+    SET body = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, body_tsv, in_reply_to, thread_id, kind, original_id, deleted_at, hidden_at
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID
+	Body string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.InReplyTo,
+		&i.ThreadID,
+		&i.Kind,
+		&i.OriginalID,
+		&i.DeletedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
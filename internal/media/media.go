@@ -0,0 +1,54 @@
+// Package media inspects uploaded images, strips the metadata they carry
+// and stores them.
+package media
+
+import(
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+// MaxDimension bounds both sides of an image, decoding anything bigger
+// would take more memory than the upload size suggests.
+const MaxDimension = 8192
+
+var ErrUnsupportedType = errors.New("media: unsupported file type")
+
+// Extensions maps the accepted MIME types to the extension files get.
+var Extensions = map[string]string{
+	"image/jpeg":	".jpg",
+	"image/png":	".png",
+	"image/gif":	".gif",
+}
+
+type Info struct {
+	MIMEType	string
+	Width		int
+	Height		int
+}
+
+// Inspect sniffs the type of an upload from its content, ignoring whatever
+// the client claimed, and reads the image dimensions from its header.
+func Inspect(data []byte) (Info, error) {
+	mimeType := http.DetectContentType(data)
+	if _, ok := Extensions[mimeType]; !ok {
+		return Info{}, ErrUnsupportedType
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Info{}, err
+	}
+	// the header and the decoder have to agree, polyglot files get rejected
+	if "image/" + format != mimeType {
+		return Info{}, ErrUnsupportedType
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxDimension || config.Height > MaxDimension {
+		return Info{}, errors.New("media: image dimensions out of range")
+	}
+	return Info{MIMEType: mimeType, Width: config.Width, Height: config.Height}, nil
+}
\ No newline at end of file
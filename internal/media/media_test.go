@@ -0,0 +1,143 @@
+package media
+
+import(
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	return img
+}
+
+func TestInspect(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, testImage(40, 30))
+
+	info, err := Inspect(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to inspect PNG: %v", err)
+	}
+	if info.MIMEType != "image/png" || info.Width != 40 || info.Height != 30 {
+		t.Errorf("Unexpected info %+v", info)
+	}
+
+	_, err = Inspect([]byte("<html><script>alert(1)</script></html>"))
+	if err != ErrUnsupportedType {
+		t.Errorf("Expected HTML to be rejected, got %v", err)
+	}
+}
+
+// withJPEGSegment inserts a segment right after the start of image marker.
+func withJPEGSegment(data []byte, marker byte, payload []byte) []byte {
+	segment := []byte{0xff, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload) + 2))
+	segment = append(segment, payload...)
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, testImage(16, 16), nil)
+	exif := append([]byte("Exif\x00\x00"), []byte("GPS 45.4642N 9.1900E")...)
+	data := withJPEGSegment(buf.Bytes(), 0xe1, exif)
+
+	stripped, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Failed to strip JPEG: %v", err)
+	}
+	if bytes.Contains(stripped, []byte("GPS")) {
+		t.Errorf("Expected the EXIF segment to be removed")
+	}
+	if !bytes.Equal(stripped, buf.Bytes()) {
+		t.Errorf("Expected the rest of the image to be left alone")
+	}
+	_, err = jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Errorf("Stripped JPEG doesn't decode: %v", err)
+	}
+}
+
+// withPNGChunk inserts a chunk right after IHDR.
+func withPNGChunk(data []byte, chunkType string, payload []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, payload...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(append([]byte(chunkType), payload...)))
+	// signature, then IHDR is always 13 bytes of data plus 12 of framing
+	at := 8 + 25
+	out := append([]byte{}, data[:at]...)
+	out = append(out, chunk...)
+	return append(out, data[at:]...)
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, testImage(8, 8))
+	data := withPNGChunk(buf.Bytes(), "tEXt", []byte("Author\x00Someone"))
+
+	stripped, err := StripMetadata(data, "image/png")
+	if err != nil {
+		t.Fatalf("Failed to strip PNG: %v", err)
+	}
+	if !bytes.Equal(stripped, buf.Bytes()) {
+		t.Errorf("Expected only the text chunk to be removed")
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	_, err := StripMetadata([]byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff}, "image/jpeg")
+	if err == nil {
+		t.Errorf("Expected a truncated JPEG to be rejected")
+	}
+	_, err = StripMetadata([]byte("\x89PNG\r\n\x1a\n\x00\x00"), "image/png")
+	if err == nil {
+		t.Errorf("Expected a truncated PNG to be rejected")
+	}
+}
+
+func TestDiskStorage(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewDiskStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	err = storage.Save(ctx, "photo.png", bytes.NewReader([]byte("pixels")))
+	if err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	r, err := storage.Open(ctx, "photo.png")
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "pixels" {
+		t.Errorf("Unexpected content %q", data)
+	}
+
+	if storage.Delete(ctx, "photo.png") != nil || storage.Delete(ctx, "photo.png") != nil {
+		t.Errorf("Expected deleting to succeed, even twice")
+	}
+	if _, err := storage.Open(ctx, "photo.png"); err == nil {
+		t.Errorf("Expected the file to be gone")
+	}
+
+	for _, key := range []string{"", "..", "../escape.png", "dir/photo.png"} {
+		if storage.Save(ctx, key, bytes.NewReader(nil)) != ErrInvalidKey {
+			t.Errorf("Expected key %q to be rejected", key)
+		}
+	}
+}
\ No newline at end of file
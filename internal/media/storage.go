@@ -0,0 +1,84 @@
+package media
+
+import(
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Storage keeps uploaded files under opaque keys. DiskStorage is the only
+// implementation for now, an object store can take its place later.
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var ErrInvalidKey = errors.New("media: invalid storage key")
+
+// DiskStorage keeps every file directly in one directory.
+type DiskStorage struct {
+	root string
+}
+
+func NewDiskStorage(root string) (*DiskStorage, error) {
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskStorage{root: root}, nil
+}
+
+func (d *DiskStorage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key == "." || key == ".." {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(d.root, key), nil
+}
+
+// Save writes to a temporary file first, so a failed upload never leaves
+// a truncated file behind under the final key.
+func (d *DiskStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(d.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *DiskStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (d *DiskStorage) Delete(ctx context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,102 @@
+package media
+
+import(
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("media: malformed image")
+
+// StripMetadata removes EXIF and other embedded metadata, like the
+// location a photo was taken at, without re-encoding the image.
+func StripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/gif":
+		// GIF has no standard place for camera metadata
+		return data, nil
+	}
+	return nil, ErrUnsupportedType
+}
+
+// stripJPEG drops the APP1 (EXIF, XMP), APP13 (IPTC) and comment segments.
+// Everything from the start of the scan on is image data and copied as is.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	i := 2
+	for {
+		if i + 4 > len(data) || data[i] != 0xff {
+			return nil, errMalformed
+		}
+		marker := data[i + 1]
+		// fill bytes may pad markers
+		if marker == 0xff {
+			i++
+			continue
+		}
+		if marker == 0xda {
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i + 2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errMalformed
+		}
+		if marker != 0xe1 && marker != 0xed && marker != 0xfe {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks are dropped, all of them are ancillary so decoders
+// don't need them.
+var pngMetadataChunks = map[string]bool{
+	"eXIf":	true,
+	"tEXt":	true,
+	"iTXt":	true,
+	"zTXt":	true,
+	"tIME":	true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+
+	i := len(pngSignature)
+	for i < len(data) {
+		if i + 8 > len(data) {
+			return nil, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		chunkType := string(data[i + 4:i + 8])
+		// length, type, data and CRC
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errMalformed
+		}
+		if !pngMetadataChunks[chunkType] {
+			out.Write(data[i:end])
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out.Bytes(), nil
+}
\ No newline at end of file
@@ -0,0 +1,244 @@
+// Package moderation finds disallowed words in user text. Matching works on
+// whole words, ignores case and punctuation around the word and sees
+// through simple leetspeak, so "K3rfuffle!" still counts as "kerfuffle".
+package moderation
+
+import(
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Action is what happens to text containing a word.
+type Action string
+
+const (
+	ActionMask		Action = "mask"
+	ActionFlag		Action = "flag"
+	ActionReject	Action = "reject"
+)
+
+// Mask replaces every masked word.
+const Mask = "****"
+
+// severity orders the actions, when a word is listed twice the harsher
+// action wins.
+var severity = map[Action]int{
+	ActionMask:		1,
+	ActionFlag:		2,
+	ActionReject:	3,
+}
+
+// Valid reports whether a is one of the known actions.
+func (a Action) Valid() bool {
+	return severity[a] > 0
+}
+
+type Rule struct {
+	Word	string
+	Action	Action
+}
+
+type Match struct {
+	Word	string
+	Action	Action
+}
+
+// Result is the outcome of checking a text, with masked words already
+// replaced. Everything outside the masked words is left as it was.
+type Result struct {
+	Text	string
+	Matches	[]Match
+}
+
+// Rejected reports whether the text contains a word that isn't allowed at all.
+func (r Result) Rejected() bool {
+	return r.has(ActionReject)
+}
+
+// Flagged reports whether the text contains a word that needs a review.
+func (r Result) Flagged() bool {
+	return r.has(ActionFlag)
+}
+
+func (r Result) has(action Action) bool {
+	for _, m := range r.Matches {
+		if m.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter is safe for concurrent use, it's never changed once built.
+type Filter struct {
+	actions	map[string]Action
+}
+
+// NewFilter builds a filter out of rules. Words must be single words, the
+// action defaults to masking.
+func NewFilter(rules []Rule) (*Filter, error) {
+	f := &Filter{actions: map[string]Action{}}
+	for _, rule := range rules {
+		if rule.Action == "" {
+			rule.Action = ActionMask
+		}
+		if !rule.Action.Valid() {
+			return nil, fmt.Errorf("invalid action %q for %q", rule.Action, rule.Word)
+		}
+		word := Normalize(rule.Word)
+		if word == "" || strings.ContainsFunc(rule.Word, unicode.IsSpace) {
+			return nil, fmt.Errorf("invalid word %q", rule.Word)
+		}
+		if severity[rule.Action] > severity[f.actions[word]] {
+			f.actions[word] = rule.Action
+		}
+	}
+	return f, nil
+}
+
+// Len returns how many distinct words the filter knows.
+func (f *Filter) Len() int {
+	return len(f.actions)
+}
+
+// Check looks for listed words in text and masks those that call for it.
+func (f *Filter) Check(text string) Result {
+	result := Result{}
+	var cleaned strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			cleaned.WriteRune(runes[i])
+			i++
+			continue
+		}
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		start, stop, action := f.match(runes[i:end])
+		if action == "" {
+			cleaned.WriteString(string(runes[i:end]))
+			i = end
+			continue
+		}
+		result.Matches = append(result.Matches, Match{Word: string(runes[i+start : i+stop]), Action: action})
+		cleaned.WriteString(string(runes[i : i+start]))
+		if action == ActionMask {
+			cleaned.WriteString(Mask)
+		} else {
+			cleaned.WriteString(string(runes[i+start : i+stop]))
+		}
+		cleaned.WriteString(string(runes[i+stop : end]))
+		i = end
+	}
+	result.Text = cleaned.String()
+	return result
+}
+
+// match tries the whole token first and then the token without the leet
+// symbols at its edges, so "$harbert" and "fornax!" both match while the
+// "!" in the latter stays punctuation. It returns the matched span.
+func (f *Filter) match(token []rune) (int, int, Action) {
+	if action, ok := f.actions[Normalize(string(token))]; ok {
+		return 0, len(token), action
+	}
+	start, stop := 0, len(token)
+	for start < stop && isLeetSymbol(token[start]) {
+		start++
+	}
+	for stop > start && isLeetSymbol(token[stop-1]) {
+		stop--
+	}
+	if start == 0 && stop == len(token) || start == stop {
+		return 0, 0, ""
+	}
+	if action, ok := f.actions[Normalize(string(token[start:stop]))]; ok {
+		return start, stop, action
+	}
+	return 0, 0, ""
+}
+
+var leet = map[rune]rune{
+	'0':	'o',
+	'1':	'i',
+	'3':	'e',
+	'4':	'a',
+	'5':	's',
+	'7':	't',
+	'@':	'a',
+	'$':	's',
+	'!':	'i',
+}
+
+func isLeetSymbol(r rune) bool {
+	_, ok := leet[r]
+	return ok && !unicode.IsNumber(r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r) || isLeetSymbol(r)
+}
+
+// Normalize undoes leetspeak and folds case, so every spelling of a word
+// ends up the same. Words are stored and looked up in this form.
+func Normalize(word string) string {
+	return strings.Map(func(r rune) rune {
+		if plain, ok := leet[r]; ok {
+			r = plain
+		}
+		return foldRune(r)
+	}, strings.TrimSpace(word))
+}
+
+// foldRune maps every case variant of r to the same lowercase rune, which
+// also covers letters like the Kelvin sign or the final sigma that
+// lowercasing alone leaves apart.
+func foldRune(r rune) rune {
+	return unicode.ToLower(unicode.ToUpper(r))
+}
+
+// ParseRules reads one word per line, optionally followed by its action.
+// Blank lines and lines starting with '#' are skipped.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a word and an optional action", line)
+		}
+		rule := Rule{Word: fields[0], Action: ActionMask}
+		if len(fields) == 2 {
+			rule.Action = Action(strings.ToLower(fields[1]))
+			if !rule.Action.Valid() {
+				return nil, fmt.Errorf("line %d: invalid action %q", line, fields[1])
+			}
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// LoadFile reads rules from a word list file, see ParseRules for the format.
+func LoadFile(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseRules(file)
+}
\ No newline at end of file
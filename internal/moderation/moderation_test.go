@@ -0,0 +1,126 @@
+package moderation
+
+import(
+	"strings"
+	"testing"
+)
+
+func testFilter(t *testing.T) *Filter {
+	t.Helper()
+	f, err := NewFilter([]Rule{
+		{Word: "kerfuffle"},
+		{Word: "sharbert", Action: ActionMask},
+		{Word: "fornax", Action: ActionReject},
+		{Word: "grawlix", Action: ActionFlag},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return f
+}
+
+func TestCheckMasks(t *testing.T) {
+	f := testFilter(t)
+	cases := []struct{
+		text		string
+		expected	string
+	}{
+		{"nothing to see here", "nothing to see here"},
+		{"What a kerfuffle", "What a ****"},
+		{"Kerfuffle! Such a KERFUFFLE.", "****! Such a ****."},
+		{"k3rfuffl3 and $harbert", "**** and ****"},
+		{"(sh@rbert), @kerfuffle", "(****), @****"},
+		{"kerfuffles aren't kerfuffle's", "kerfuffles aren't ****'s"},
+	}
+	for _, c := range cases {
+		result := f.Check(c.text)
+		if result.Text != c.expected {
+			t.Errorf("Check(%q) = %q, expected %q", c.text, result.Text, c.expected)
+		}
+	}
+}
+
+func TestCheckActions(t *testing.T) {
+	f := testFilter(t)
+
+	result := f.Check("the fornax is out")
+	if !result.Rejected() || result.Flagged() {
+		t.Errorf("Expected the text to be rejected only, got %+v", result.Matches)
+	}
+
+	result = f.Check("a Grawlix, kept as is")
+	if result.Rejected() || !result.Flagged() {
+		t.Errorf("Expected the text to be flagged only, got %+v", result.Matches)
+	}
+	if result.Text != "a Grawlix, kept as is" {
+		t.Errorf("Expected flagged words to be left alone, got %q", result.Text)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Word != "Grawlix" {
+		t.Errorf("Expected a match on Grawlix, got %+v", result.Matches)
+	}
+}
+
+func TestCaseFolding(t *testing.T) {
+	f, err := NewFilter([]Rule{{Word: "straße"}, {Word: "kelvin"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// the first letter of the second word is the Kelvin sign
+	result := f.Check("STRAẞE Kelvin")
+	if result.Text != "**** ****" {
+		t.Errorf("Expected both words masked, got %q", result.Text)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if word := Normalize(" K3rfuff!e "); word != "kerfuffie" {
+		t.Errorf("Expected kerfuffie, got %s", word)
+	}
+	if Normalize("ΣΟΦΟΣ") != Normalize("σοφος") || Normalize("σοφος") != Normalize("σοφοσ") {
+		t.Errorf("Expected the Greek sigmas to fold together")
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	f, err := NewFilter([]Rule{{Word: "Fornax", Action: ActionMask}, {Word: "f0rnax", Action: ActionReject}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if f.Len() != 1 || !f.Check("fornax").Rejected() {
+		t.Errorf("Expected the harsher action to win for repeated words")
+	}
+
+	_, err = NewFilter([]Rule{{Word: "two words"}})
+	if err == nil {
+		t.Errorf("Expected an error for a phrase")
+	}
+	_, err = NewFilter([]Rule{{Word: "fornax", Action: "ban"}})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown action")
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(strings.NewReader("# word list\nkerfuffle\n\nfornax reject\n  grawlix FLAG  \n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []Rule{
+		{Word: "kerfuffle", Action: ActionMask},
+		{Word: "fornax", Action: ActionReject},
+		{Word: "grawlix", Action: ActionFlag},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("Expected %d rules, got %d", len(expected), len(rules))
+	}
+	for i := range expected {
+		if rules[i] != expected[i] {
+			t.Errorf("Rule %d: expected %+v, got %+v", i, expected[i], rules[i])
+		}
+	}
+
+	_, err = ParseRules(strings.NewReader("fornax ban\n"))
+	if err == nil {
+		t.Errorf("Expected an error for an unknown action")
+	}
+}
\ No newline at end of file
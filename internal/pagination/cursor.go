@@ -0,0 +1,54 @@
+package pagination
+
+import(
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Direction string
+
+const (
+	Next Direction = "next"
+	Prev Direction = "prev"
+)
+
+// Cursor marks a position in a listing ordered by (created_at, id), or by
+// (rank, created_at, id) for search results.
+// Clients only ever see it in its encoded, opaque form.
+type Cursor struct {
+	Direction	Direction	`json:"d"`
+	Rank		float32		`json:"r,omitempty"`
+	CreatedAt	time.Time	`json:"t"`
+	ID			uuid.UUID	`json:"i"`
+}
+
+func Encode(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func Decode(s string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errors.New("Cursor is not valid base64")
+	}
+
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return c, errors.New("Cursor can't be decoded")
+	}
+
+	if c.Direction != Next && c.Direction != Prev {
+		return c, errors.New("Cursor has an unknown direction")
+	}
+	if c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return c, errors.New("Cursor is missing its position")
+	}
+
+	return c, nil
+}
\ No newline at end of file
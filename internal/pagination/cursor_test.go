@@ -0,0 +1,41 @@
+package pagination
+
+import(
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cases := []Cursor{
+		{Direction: Next, CreatedAt: time.Date(2025, 8, 1, 12, 30, 0, 123456000, time.UTC), ID: uuid.New()},
+		{Direction: Prev, CreatedAt: time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC), ID: uuid.New()},
+		{Direction: Next, Rank: 0.0607927, CreatedAt: time.Now().UTC(), ID: uuid.New()},
+	}
+	for _, c := range cases {
+		decoded, err := Decode(Encode(c))
+		if err != nil {
+			t.Errorf("Failed to decode cursor: %v", err)
+			continue
+		}
+		if decoded.Direction != c.Direction || decoded.Rank != c.Rank || !decoded.CreatedAt.Equal(c.CreatedAt) || decoded.ID != c.ID {
+			t.Errorf("Decoded cursor %+v doesn't match %+v", decoded, c)
+		}
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	cases := []string{
+		"",
+		"not base64!",
+		Encode(Cursor{Direction: "sideways", CreatedAt: time.Now(), ID: uuid.New()}),
+		Encode(Cursor{Direction: Next}),
+	}
+	for _, c := range cases {
+		_, err := Decode(c)
+		if err == nil {
+			t.Errorf("Expected cursor %q to be rejected", c)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,126 @@
+package stream
+
+import(
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is how many events a subscriber can fall behind before
+// it gets dropped, clients are expected to reconnect with Last-Event-ID.
+const subscriberBuffer = 64
+
+// recentEvents bounds how many event IDs the hub remembers to drop
+// duplicates, the same event can come from a handler and from the database.
+const recentEvents = 1024
+
+// Event is something that happened, as sent to subscribers. Events with a
+// recipient are private to that user, the others are public.
+type Event struct {
+	ID			int64			`json:"id"`
+	Kind		string			`json:"kind"`
+	AuthorID	uuid.UUID		`json:"author_id"`
+	Recipient	uuid.NullUUID	`json:"recipient"`
+	Data		json.RawMessage	`json:"data"`
+}
+
+type Subscription struct {
+	C			<-chan Event
+	events		chan Event
+	authorID	uuid.NullUUID
+	userID		uuid.NullUUID
+	hub			*Hub
+}
+
+// Hub fans events out to every subscriber in the process.
+type Hub struct {
+	mu			sync.Mutex
+	subscribers	map[*Subscription]struct{}
+	seen		map[int64]struct{}
+	order		[]int64
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers:	map[*Subscription]struct{}{},
+		seen:			map[int64]struct{}{},
+	}
+}
+
+// Subscribe returns a subscription to all public events, or only to those
+// of one author when authorID is set.
+func (h *Hub) Subscribe(authorID uuid.NullUUID) *Subscription {
+	return h.subscribe(&Subscription{authorID: authorID})
+}
+
+// SubscribeUser returns a subscription to all public events and to the
+// private events of one user.
+func (h *Hub) SubscribeUser(userID uuid.UUID) *Subscription {
+	return h.subscribe(&Subscription{userID: uuid.NullUUID{UUID: userID, Valid: true}})
+}
+
+func (h *Hub) subscribe(s *Subscription) *Subscription {
+	s.events = make(chan Event, subscriberBuffer)
+	s.C = s.events
+	s.hub = h
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[s] = struct{}{}
+	return s
+}
+
+// Close removes the subscription from the hub and closes its channel, it's
+// safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (h *Hub) remove(s *Subscription) {
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+// Publish delivers an event to the matching subscribers. It reports false
+// when the event was already published, events without an ID are never
+// considered duplicates. It never blocks: subscribers that can't keep up
+// are dropped.
+func (h *Hub) Publish(e Event) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if e.ID != 0 {
+		if _, ok := h.seen[e.ID]; ok {
+			return false
+		}
+		h.seen[e.ID] = struct{}{}
+		h.order = append(h.order, e.ID)
+		if len(h.order) > recentEvents {
+			delete(h.seen, h.order[0])
+			h.order = h.order[1:]
+		}
+	}
+
+	for s := range h.subscribers {
+		if !s.matches(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			h.remove(s)
+		}
+	}
+	return true
+}
+func (s *Subscription) matches(e Event) bool {
+	if e.Recipient.Valid {
+		return s.userID.Valid && s.userID.UUID == e.Recipient.UUID
+	}
+	return !s.authorID.Valid || s.authorID.UUID == e.AuthorID
+}
\ No newline at end of file
@@ -0,0 +1,92 @@
+package stream
+
+import(
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPublishReachesSubscribers(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe(uuid.NullUUID{})
+	defer all.Close()
+
+	author := uuid.New()
+	own := hub.Subscribe(uuid.NullUUID{UUID: author, Valid: true})
+	defer own.Close()
+
+	hub.Publish(Event{ID: 1, Kind: "chirp.created", AuthorID: uuid.New()})
+	hub.Publish(Event{ID: 2, Kind: "chirp.created", AuthorID: author})
+
+	for _, want := range []int64{1, 2} {
+		e := <-all.C
+		if e.ID != want {
+			t.Errorf("Expected event %d, got %d", want, e.ID)
+		}
+	}
+
+	e := <-own.C
+	if e.ID != 2 {
+		t.Errorf("Expected only the author's event, got %d", e.ID)
+	}
+	if len(own.C) != 0 {
+		t.Errorf("Expected no more events for the author filter, got %d", len(own.C))
+	}
+}
+
+func TestPublishDropsDuplicates(t *testing.T) {
+	hub := NewHub()
+	s := hub.Subscribe(uuid.NullUUID{})
+	defer s.Close()
+
+	if !hub.Publish(Event{ID: 7}) {
+		t.Errorf("Expected the first publish to go through")
+	}
+	if hub.Publish(Event{ID: 7}) {
+		t.Errorf("Expected the duplicate to be dropped")
+	}
+	if len(s.C) != 1 {
+		t.Errorf("Expected a single event, got %d", len(s.C))
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub()
+	s := hub.Subscribe(uuid.NullUUID{})
+
+	for i := int64(1); i <= subscriberBuffer + 1; i++ {
+		hub.Publish(Event{ID: i})
+	}
+
+	received := 0
+	for range s.C {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("Expected %d buffered events before the drop, got %d", subscriberBuffer, received)
+	}
+
+	// closing after the hub dropped it must not panic
+	s.Close()
+}
+func TestPrivateEventsOnlyReachTheirRecipient(t *testing.T) {
+	hub := NewHub()
+	user := uuid.New()
+	mine := hub.SubscribeUser(user)
+	defer mine.Close()
+	other := hub.SubscribeUser(uuid.New())
+	defer other.Close()
+	public := hub.Subscribe(uuid.NullUUID{})
+	defer public.Close()
+
+	hub.Publish(Event{Kind: "notification", Recipient: uuid.NullUUID{UUID: user, Valid: true}})
+	hub.Publish(Event{Kind: "notification", Recipient: uuid.NullUUID{UUID: user, Valid: true}})
+	hub.Publish(Event{ID: 1, Kind: "chirp.created", AuthorID: uuid.New()})
+
+	if len(mine.C) != 3 {
+		t.Errorf("Expected both notifications and the public event, got %d events", len(mine.C))
+	}
+	if len(other.C) != 1 || len(public.C) != 1 {
+		t.Errorf("Expected only the public event elsewhere, got %d and %d", len(other.C), len(public.C))
+	}
+}
\ No newline at end of file
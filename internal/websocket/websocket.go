@@ -0,0 +1,285 @@
+// Package websocket implements the server side of RFC 6455, just enough to
+// exchange text messages with browsers and other clients.
+package websocket
+
+import(
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ContinuationMessage	= 0
+	TextMessage			= 1
+	BinaryMessage		= 2
+	CloseMessage		= 8
+	PingMessage			= 9
+	PongMessage			= 10
+)
+
+const (
+	CloseNormal			= 1000
+	CloseGoingAway		= 1001
+	CloseProtocolError	= 1002
+	ClosePolicyViolation	= 1008
+	CloseMessageTooBig	= 1009
+	CloseTryAgainLater	= 1013
+)
+
+// DefaultMaxMessageSize caps incoming messages, fragmented ones included.
+const DefaultMaxMessageSize = 64 * 1024
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	ErrClosed			= errors.New("websocket: connection closed")
+	ErrMessageTooBig	= errors.New("websocket: message too big")
+	errProtocol			= errors.New("websocket: protocol error")
+)
+
+type Conn struct {
+	conn			net.Conn
+	br				*bufio.Reader
+	wmu				sync.Mutex
+	readTimeout		time.Duration
+	MaxMessageSize	int64
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept header for a client's
+// Sec-WebSocket-Key.
+func AcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Upgrade completes the opening handshake and takes over the connection.
+// When the request isn't a valid handshake nothing is written, so the
+// caller can still respond to it.
+func Upgrade(rw http.ResponseWriter, req *http.Request) (*Conn, error) {
+	if req.Method != http.MethodGet {
+		return nil, errors.New("websocket: handshake must be a GET request")
+	}
+	if !headerContains(req.Header, "Connection", "upgrade") || !headerContains(req.Header, "Upgrade", "websocket") {
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket: connection can't be hijacked")
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	_, err = netConn.Write([]byte(response))
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return newConn(netConn, brw.Reader), nil
+}
+
+func newConn(netConn net.Conn, br *bufio.Reader) *Conn {
+	if br == nil {
+		br = bufio.NewReader(netConn)
+	}
+	return &Conn{conn: netConn, br: br, MaxMessageSize: DefaultMaxMessageSize}
+}
+
+func headerContains(h http.Header, name, value string) bool {
+	for _, v := range h.Values(name) {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetReadTimeout makes every frame read, control frames included, fail
+// when the peer stays silent for longer than d. Zero disables it.
+func (c *Conn) SetReadTimeout(d time.Duration) {
+	c.readTimeout = d
+}
+
+// ReadMessage returns the next text or binary message, reassembling
+// fragments. Pings are answered and pongs skipped along the way. Once the
+// peer closes the connection it returns ErrClosed.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	opcode := -1
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			if errors.Is(err, ErrMessageTooBig) {
+				c.WriteClose(CloseMessageTooBig, "")
+			} else if errors.Is(err, errProtocol) {
+				c.WriteClose(CloseProtocolError, "")
+			}
+			return 0, nil, err
+		}
+
+		switch op {
+		case PingMessage:
+			err = c.WriteMessage(PongMessage, payload)
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.WriteClose(code, "")
+			return 0, nil, ErrClosed
+		case TextMessage, BinaryMessage:
+			if opcode != -1 {
+				c.WriteClose(CloseProtocolError, "")
+				return 0, nil, errProtocol
+			}
+			opcode = op
+		case ContinuationMessage:
+			if opcode == -1 {
+				c.WriteClose(CloseProtocolError, "")
+				return 0, nil, errProtocol
+			}
+		default:
+			c.WriteClose(CloseProtocolError, "")
+			return 0, nil, errProtocol
+		}
+
+		if int64(len(message) + len(payload)) > c.MaxMessageSize {
+			c.WriteClose(CloseMessageTooBig, "")
+			return 0, nil, ErrMessageTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	if c.readTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(c.br, header)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0] & 0x80 != 0
+	if header[0] & 0x70 != 0 {
+		// no extensions are negotiated, so reserved bits must be clear
+		return false, 0, nil, errProtocol
+	}
+	opcode := int(header[0] & 0x0f)
+	masked := header[1] & 0x80 != 0
+	length := int64(header[1] & 0x7f)
+
+	// clients must mask everything they send
+	if !masked {
+		return false, 0, nil, errProtocol
+	}
+	if opcode >= CloseMessage && (!fin || length > 125) {
+		return false, 0, nil, errProtocol
+	}
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		_, err = io.ReadFull(c.br, ext)
+		length = int64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		_, err = io.ReadFull(c.br, ext)
+		length = int64(binary.BigEndian.Uint64(ext))
+	}
+	if err != nil {
+		return false, 0, nil, err
+	}
+	if length < 0 || length > c.MaxMessageSize {
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	mask := make([]byte, 4)
+	_, err = io.ReadFull(c.br, mask)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.br, payload)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i % 4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage sends data as a single unfragmented frame, it's safe to
+// call from several goroutines.
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	frame := []byte{0x80 | byte(opcode)}
+	switch {
+	case len(data) <= 125:
+		frame = append(frame, byte(len(data)))
+	case len(data) <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(data)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(data)))
+	}
+	frame = append(frame, data...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// WriteClose starts or answers the closing handshake.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload = append(payload, reason...)
+	return c.WriteMessage(CloseMessage, payload)
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
\ No newline at end of file
@@ -0,0 +1,242 @@
+package websocket
+
+import(
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// the example handshake from RFC 6455, section 1.3
+	got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ==")
+	if got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected accept key %q", got)
+	}
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/ws", nil)
+	_, err := Upgrade(httptest.NewRecorder(), req)
+	if err == nil {
+		t.Errorf("Expected a request without upgrade headers to be rejected")
+	}
+
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	_, err = Upgrade(httptest.NewRecorder(), req)
+	if err == nil {
+		t.Errorf("Expected an unsupported version to be rejected")
+	}
+}
+
+// writeClientFrame writes a masked frame the way a client would.
+func writeClientFrame(w io.Writer, fin bool, opcode int, payload []byte) error {
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80 | byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 0x80 | 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80 | 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b ^ mask[i % 4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readServerFrame reads an unmasked frame sent by the server.
+func readServerFrame(r *bufio.Reader) (int, []byte, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, nil, err
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		ext := make([]byte, 2)
+		io.ReadFull(r, ext)
+		length = int(binary.BigEndian.Uint16(ext))
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(r, payload)
+	return int(header[0] & 0x0f), payload, err
+}
+
+func TestReadMessageReassemblesFragments(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, nil)
+	defer conn.Close()
+
+	go func() {
+		writeClientFrame(client, false, TextMessage, []byte("hello "))
+		writeClientFrame(client, true, ContinuationMessage, []byte("world"))
+	}()
+
+	opcode, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	if opcode != TextMessage || string(message) != "hello world" {
+		t.Errorf("Unexpected message %d %q", opcode, message)
+	}
+}
+
+func TestReadMessageAnswersPings(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, nil)
+	defer conn.Close()
+
+	go conn.ReadMessage()
+
+	go writeClientFrame(client, true, PingMessage, []byte("are you there"))
+	opcode, payload, err := readServerFrame(bufio.NewReader(client))
+	if err != nil {
+		t.Fatalf("Failed to read pong: %v", err)
+	}
+	if opcode != PongMessage || string(payload) != "are you there" {
+		t.Errorf("Unexpected reply %d %q", opcode, payload)
+	}
+}
+
+func TestReadMessageClosingHandshake(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, nil)
+	defer conn.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		errs <- err
+	}()
+
+	go writeClientFrame(client, true, CloseMessage, binary.BigEndian.AppendUint16(nil, CloseGoingAway))
+	opcode, payload, err := readServerFrame(bufio.NewReader(client))
+	if err != nil {
+		t.Fatalf("Failed to read close frame: %v", err)
+	}
+	if opcode != CloseMessage || binary.BigEndian.Uint16(payload) != CloseGoingAway {
+		t.Errorf("Unexpected reply %d %v", opcode, payload)
+	}
+	if err := <-errs; !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestReadMessageTooBig(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, nil)
+	conn.MaxMessageSize = 10
+	defer conn.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		errs <- err
+	}()
+
+	go writeClientFrame(client, true, TextMessage, []byte("way more than ten bytes"))
+	opcode, payload, _ := readServerFrame(bufio.NewReader(client))
+	if opcode != CloseMessage || binary.BigEndian.Uint16(payload) != CloseMessageTooBig {
+		t.Errorf("Unexpected reply %d %v", opcode, payload)
+	}
+	if err := <-errs; !errors.Is(err, ErrMessageTooBig) {
+		t.Errorf("Expected ErrMessageTooBig, got %v", err)
+	}
+}
+
+func TestWriteMessageLengths(t *testing.T) {
+	for _, size := range []int{0, 125, 126, 70000} {
+		server, client := net.Pipe()
+		conn := newConn(server, nil)
+
+		data := make([]byte, size)
+		go conn.WriteMessage(TextMessage, data)
+
+		r := bufio.NewReader(client)
+		header := make([]byte, 2)
+		io.ReadFull(r, header)
+		length := int(header[1] & 0x7f)
+		switch length {
+		case 126:
+			ext := make([]byte, 2)
+			io.ReadFull(r, ext)
+			length = int(binary.BigEndian.Uint16(ext))
+		case 127:
+			ext := make([]byte, 8)
+			io.ReadFull(r, ext)
+			length = int(binary.BigEndian.Uint64(ext))
+		}
+		if header[0] != 0x80 | TextMessage || header[1] & 0x80 != 0 || length != size {
+			t.Errorf("Unexpected frame header for %d bytes: %v, length %d", size, header, length)
+		}
+		io.ReadFull(r, make([]byte, length))
+
+		conn.Close()
+		client.Close()
+	}
+}
+
+var _ http.Hijacker = (*hijackRecorder)(nil)
+
+// hijackRecorder lets Upgrade take over a pipe like it would a real connection.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn	net.Conn
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, bufio.NewReadWriter(bufio.NewReader(h.conn), bufio.NewWriter(h.conn)), nil
+}
+
+func TestUpgradeHandshake(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	req := httptest.NewRequest("GET", "/api/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+	go func() {
+		conn, err := Upgrade(&hijackRecorder{httptest.NewRecorder(), server}, req)
+		if err == nil {
+			conn.WriteMessage(TextMessage, []byte("hi"))
+		}
+	}()
+
+	r := bufio.NewReader(client)
+	res, err := http.ReadResponse(r, req)
+	if err != nil {
+		t.Fatalf("Failed to read handshake response: %v", err)
+	}
+	if res.StatusCode != 101 || res.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected handshake response %d %v", res.StatusCode, res.Header)
+	}
+	opcode, payload, err := readServerFrame(r)
+	if err != nil || opcode != TextMessage || string(payload) != "hi" {
+		t.Errorf("Unexpected first message %d %q %v", opcode, payload, err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,87 @@
+package main
+
+import(
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/stream"
+)
+
+// listenChirpEvents relays the chirp events and notifications announced by
+// every server instance, this one included, to the local hub. The hub
+// drops the chirp events it already got straight from the handlers.
+// Blocklist changes make it reload the profanity filter instead.
+func (cfg *apiConfig) listenChirpEvents(dbURL string) {
+	listener := pq.NewListener(dbURL, 10 * time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Chirp events listener: %s", err)
+		}
+	})
+	for _, channel := range []string{chirpEventsChannel, notificationsChannel, blocklistChannel} {
+		err := listener.Listen(channel)
+		if err != nil {
+			log.Printf("Error listening for %s: %s", channel, err)
+			return
+		}
+	}
+
+	var lastID int64
+	for {
+		select {
+		case n := <-listener.Notify:
+			// a nil notification means the connection was re-established,
+			// whatever was announced in the meantime is fetched instead
+			if n == nil {
+				lastID = cfg.catchUpChirpEvents(lastID)
+				cfg.refreshProfanityFilter(context.Background())
+				continue
+			}
+			if n.Channel == blocklistChannel {
+				cfg.refreshProfanityFilter(context.Background())
+				continue
+			}
+			e := stream.Event{}
+			err := json.Unmarshal([]byte(n.Extra), &e)
+			if err != nil {
+				log.Printf("Error decoding a chirp event: %s", err)
+				continue
+			}
+			cfg.hub.Publish(e)
+			// notifications aren't stored as events, missed ones stay in the feed
+			if n.Channel == chirpEventsChannel {
+				lastID = max(lastID, e.ID)
+			}
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+// catchUpChirpEvents publishes the events stored after lastID and returns
+// the new last ID. Before the first event there's nothing to catch up on.
+func (cfg *apiConfig) catchUpChirpEvents(lastID int64) int64 {
+	if lastID == 0 {
+		return lastID
+	}
+	for {
+		events, err := cfg.db.ListChirpEventsAfter(context.Background(), database.ListChirpEventsAfterParams{
+			AfterID:	lastID,
+			RowLimit:	maxReplayEvents,
+		})
+		if err != nil {
+			log.Printf("Error catching up on chirp events: %s", err)
+			return lastID
+		}
+		for _, e := range events {
+			cfg.hub.Publish(mapChirpEvent(e))
+			lastID = e.ID
+		}
+		if len(events) < maxReplayEvents {
+			return lastID
+		}
+	}
+}
\ No newline at end of file
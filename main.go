@@ -1,22 +1,35 @@
 package main
 
 import(
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"sync/atomic"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/neriAle/chirpy/internal/database"
+	"github.com/neriAle/chirpy/internal/media"
+	"github.com/neriAle/chirpy/internal/moderation"
+	"github.com/neriAle/chirpy/internal/stream"
 	_ "github.com/lib/pq"
 )
 
 type apiConfig struct {
 	fileserverHits 	atomic.Int32
 	db 				*database.Queries
+	dbConn			*sql.DB
 	platform 		string
 	tokenSecret 	string
 	polka_key		string
+	restoreWindow	time.Duration
+	chirpRetention	time.Duration
+	hub				*stream.Hub
+	mediaStorage	media.Storage
+	profanityRules	[]moderation.Rule
+	profanity		atomic.Pointer[moderation.Filter]
 }
 
 func main() {
@@ -25,6 +38,13 @@ func main() {
 	if dbURL == "" {
 		log.Fatal("DB_URL must be set")
 	}
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		err := createAdmin(dbURL, os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	platform := os.Getenv("PLATFORM")
 	if platform == "" {
 		log.Fatal("PLATFORM must be set")
@@ -38,6 +58,40 @@ func main() {
 		log.Fatal("POLKA_KEY must be set")
 	}
 
+	restoreWindow, err := durationFromEnv("CHIRP_RESTORE_WINDOW", 24 * time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chirpRetention, err := durationFromEnv("CHIRP_RETENTION", 30 * 24 * time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if chirpRetention < restoreWindow {
+		log.Fatal("CHIRP_RETENTION can't be shorter than CHIRP_RESTORE_WINDOW")
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "media"
+	}
+	mediaStorage, err := media.NewDiskStorage(mediaDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	profanityRules := defaultProfaneWords
+	profanityFile := os.Getenv("PROFANITY_FILE")
+	if profanityFile != "" {
+		profanityRules, err = moderation.LoadFile(profanityFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	profanity, err := moderation.NewFilter(profanityRules)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatal(err)
@@ -47,10 +101,38 @@ func main() {
 	apiCfg := apiConfig{
 		fileserverHits: atomic.Int32{},
 		db: dbQueries,
+		dbConn: db,
 		platform: platform,
 		tokenSecret: tokenSecret,
 		polka_key: polka,
+		restoreWindow: restoreWindow,
+		chirpRetention: chirpRetention,
+		hub: stream.NewHub(),
+		mediaStorage: mediaStorage,
+		profanityRules: profanityRules,
 	}
+	// the file alone until the stored words are loaded, so a database
+	// that's down at startup doesn't leave chirps unfiltered
+	apiCfg.profanity.Store(profanity)
+	err = apiCfg.loadProfanityFilter(context.Background())
+	if err != nil {
+		log.Printf("Error loading the stored profane words: %s", err)
+	}
+
+	go apiCfg.listenChirpEvents(dbURL)
+	startServer(&apiCfg)
+}
 
-	startServer(apiCfg)
+// durationFromEnv reads an optional duration like "72h", falling back to
+// the default when the variable isn't set.
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration", key)
+	}
+	return d, nil
 }
\ No newline at end of file
@@ -0,0 +1,126 @@
+package main
+
+import(
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/pagination"
+)
+
+const defaultPageLimit = 20
+const maxPageLimit = 100
+
+type pageParams struct {
+	limit	int32
+	cursor	*pagination.Cursor
+}
+
+func parsePageParams(req *http.Request) (pageParams, error) {
+	page := pageParams{limit: defaultPageLimit}
+
+	l := req.URL.Query().Get("limit")
+	if l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, fmt.Errorf("Invalid limit, must be between 1 and %d", maxPageLimit)
+		}
+		page.limit = int32(limit)
+	}
+
+	c := req.URL.Query().Get("cursor")
+	if c != "" {
+		cursor, err := pagination.Decode(c)
+		if err != nil {
+			return page, errors.New("Invalid cursor")
+		}
+		page.cursor = &cursor
+	}
+
+	return page, nil
+}
+
+func parseTimeParam(req *http.Request, name string) (sql.NullTime, error) {
+	s := req.URL.Query().Get(name)
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// backwards reports whether the page is being walked towards its start,
+// in which case the rows have to be fetched in reverse order.
+func (page pageParams) backwards() bool {
+	return page.cursor != nil && page.cursor.Direction == pagination.Prev
+}
+
+// position returns the (created_at, id) key of the cursor in the shape
+// the keyset queries expect, or nulls for the first page.
+func (page pageParams) position() (sql.NullTime, uuid.NullUUID) {
+	if page.cursor == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: page.cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: page.cursor.ID, Valid: true}
+}
+
+// paginate trims the limit+1 rows fetched for a page and works out the
+// cursors of the pages around it. Rows fetched for a prev cursor come in
+// reverse order and are flipped back here.
+func paginate[T any](rows []T, page pageParams, position func(T) pagination.Cursor) ([]T, *pagination.Cursor, *pagination.Cursor) {
+	hasMore := len(rows) > int(page.limit)
+	if hasMore {
+		rows = rows[:page.limit]
+	}
+	if page.backwards() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, nil, nil
+	}
+
+	var next, prev *pagination.Cursor
+	if hasMore || page.backwards() {
+		c := position(rows[len(rows)-1])
+		c.Direction = pagination.Next
+		next = &c
+	}
+	if (page.backwards() && hasMore) || (page.cursor != nil && !page.backwards()) {
+		c := position(rows[0])
+		c.Direction = pagination.Prev
+		prev = &c
+	}
+	return rows, next, prev
+}
+
+// setPaginationLinks points the Link header at the pages around the
+// current one, keeping every other query parameter of the request.
+func setPaginationLinks(rw http.ResponseWriter, req *http.Request, next, prev *pagination.Cursor) {
+	links := []string{}
+	for _, l := range []struct{
+		rel		string
+		cursor	*pagination.Cursor
+	}{{"next", next}, {"prev", prev}} {
+		if l.cursor == nil {
+			continue
+		}
+		query := req.URL.Query()
+		query.Set("cursor", pagination.Encode(*l.cursor))
+		u := *req.URL
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), l.rel))
+	}
+	if len(links) > 0 {
+		rw.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,53 @@
+package main
+
+import(
+	"context"
+	"log"
+	"time"
+
+	"github.com/neriAle/chirpy/internal/moderation"
+)
+
+// defaultProfaneWords are used when no word list file is configured.
+var defaultProfaneWords = []moderation.Rule{
+	{Word: "kerfuffle", Action: moderation.ActionMask},
+	{Word: "sharbert", Action: moderation.ActionMask},
+	{Word: "fornax", Action: moderation.ActionMask},
+}
+
+// loadProfanityFilter rebuilds the profanity filter out of the word list
+// file and the words stored in the database, then swaps it in. Requests
+// already running keep the filter they started with.
+func (cfg *apiConfig) loadProfanityFilter(ctx context.Context) error {
+	rules := append([]moderation.Rule{}, cfg.profanityRules...)
+	words, err := cfg.db.ListProfaneWords(ctx)
+	if err != nil {
+		return err
+	}
+	for _, w := range words {
+		rules = append(rules, moderation.Rule{Word: w.Word, Action: moderation.Action(w.Action)})
+	}
+
+	filter, err := moderation.NewFilter(rules)
+	if err != nil {
+		return err
+	}
+	cfg.profanity.Store(filter)
+	return nil
+}
+
+// reloadProfanityFilter picks up changes to the stored words once per
+// interval. Changes made through the blocklist API are announced and
+// picked up right away, this only covers announcements that got lost.
+func (cfg *apiConfig) reloadProfanityFilter(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		err := cfg.loadProfanityFilter(context.Background())
+		if err != nil {
+			log.Printf("Error reloading the profanity filter: %s", err)
+		}
+	}
+}
\ No newline at end of file
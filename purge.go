@@ -0,0 +1,42 @@
+package main
+
+import(
+	"context"
+	"log"
+	"time"
+)
+
+// purgeDeletedChirps hard-deletes chirps that have been soft-deleted for
+// longer than the retention window, checking once per interval. Thread
+// roots stay around as tombstones until their replies are gone, and
+// reported chirps until their reports are handled. Stream
+// events too old to resume from go at the same time, and so do uploads
+// that aren't attached to any chirp or draft, files included.
+func (cfg *apiConfig) purgeDeletedChirps(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := cfg.db.PurgeDeletedChirps(context.Background(), time.Now().UTC().Add(-cfg.chirpRetention))
+		if err != nil {
+			log.Printf("Error purging deleted chirps: %s", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted chirps", purged)
+		}
+		_, err = cfg.db.PurgeChirpEvents(context.Background(), time.Now().UTC().Add(-chirpEventRetention))
+		if err != nil {
+			log.Printf("Error purging chirp events: %s", err)
+		}
+		keys, err := cfg.db.PurgeUnattachedMedia(context.Background(), time.Now().UTC().Add(-unattachedMediaRetention))
+		if err != nil {
+			log.Printf("Error purging unattached media: %s", err)
+		}
+		for _, key := range keys {
+			err = cfg.mediaStorage.Delete(context.Background(), key)
+			if err != nil {
+				log.Printf("Error deleting the stored file %s: %s", key, err)
+			}
+		}
+		<-ticker.C
+	}
+}
\ No newline at end of file
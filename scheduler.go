@@ -0,0 +1,84 @@
+package main
+
+import(
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/neriAle/chirpy/internal/database"
+)
+
+// publishScheduledChirps publishes scheduled chirps once they're due,
+// checking once per interval. The schedule lives in the database, so
+// chirps that came due while the server was down go out on the first
+// check after it's back, and several servers can run this side by side.
+// The chirps of suspended users wait until the suspension is over.
+func (cfg *apiConfig) publishScheduledChirps(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			published, err := cfg.publishDueChirp(context.Background())
+			if err != nil {
+				log.Printf("Error publishing a scheduled chirp: %s", err)
+				break
+			}
+			if !published {
+				break
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// publishDueChirp publishes the next due chirp, if there is one. A chirp
+// that fails its checks is turned back into a plain draft with the reason,
+// so the author can fix it, instead of being retried forever.
+func (cfg *apiConfig) publishDueChirp(ctx context.Context) (bool, error) {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	draft, err := qtx.ClaimDueDraft(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, event, err := cfg.publishDraft(ctx, qtx, draft)
+	var checkErr *chirpCheckError
+	if errors.As(err, &checkErr) {
+		// the failed insert aborted the transaction, so the draft is marked
+		// outside of it
+		tx.Rollback()
+		err = cfg.db.FailDraft(ctx, database.FailDraftParams{
+			ID:			draft.ID,
+			Failure:	sql.NullString{String: checkErr.msg, Valid: true},
+		})
+		if err != nil {
+			return false, err
+		}
+		log.Printf("Scheduled chirp %s was turned down: %s", draft.ID, checkErr.msg)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	if event != nil {
+		cfg.hub.Publish(*event)
+	}
+	return true, nil
+}
\ No newline at end of file
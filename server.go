@@ -7,6 +7,7 @@ import(
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
 )
 
 type User struct {
@@ -15,40 +16,124 @@ type User struct {
 	UpdatedAt 	time.Time 	`json:"updated_at"`
 	Email     	string    	`json:"email"`
 	IsChirpyRed	bool		`json:"is_chirpy_red"`
+	Handle		string		`json:"handle,omitempty"`
+	Role		string		`json:"role,omitempty"`
 }
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string	`json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID        	uuid.UUID 		`json:"id"`
+	CreatedAt 	time.Time 		`json:"created_at"`
+	UpdatedAt 	time.Time 		`json:"updated_at"`
+	Body      	string			`json:"body"`
+	UserID    	uuid.UUID 		`json:"user_id"`
+	InReplyTo	uuid.NullUUID	`json:"in_reply_to"`
+	ThreadID	uuid.UUID		`json:"thread_id"`
+	ReplyCount	int64			`json:"reply_count"`
+	LikeCount	int64			`json:"like_count"`
+	LikedByMe	bool			`json:"liked_by_me"`
+	BookmarkedByMe	bool		`json:"bookmarked_by_me"`
+	Pinned		bool			`json:"pinned,omitempty"`
+	Kind		string			`json:"kind"`
+	OriginalID	uuid.NullUUID	`json:"original_id"`
+	Original	*Chirp			`json:"original,omitempty"`
+	Deleted		bool			`json:"deleted,omitempty"`
+	Media		[]Media			`json:"media,omitempty"`
+	Poll		*Poll			`json:"poll,omitempty"`
 }
 
-func startServer(apiCfg apiConfig) {
+func startServer(apiCfg *apiConfig) {
 	const filepathRoot = "."
 	const port = "9090"
 
 	servemux := http.NewServeMux()
 
 	servemux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot)))))
+	servemux.HandleFunc("GET /media/{mediaID}", apiCfg.handlerGetMedia)
 	servemux.HandleFunc("GET /api/healthz", handlerHealthz)
-	servemux.HandleFunc("GET /admin/metrics", apiCfg.handlerGetHits)
-	servemux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
+	// every /admin route needs a moderator at least, most of them an admin
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return apiCfg.middlewareRequireRole(auth.RoleAdmin, handler)
+	}
+	adminMux := http.NewServeMux()
+	adminMux.Handle("GET /admin/metrics", admin(apiCfg.handlerGetHits))
+	adminMux.Handle("POST /admin/reset", admin(apiCfg.handlerReset))
+	adminMux.Handle("PUT /admin/users/{id}/role", admin(apiCfg.handlerSetUserRole))
+	adminMux.Handle("GET /admin/blocklist", admin(apiCfg.handlerGetBlocklist))
+	adminMux.Handle("POST /admin/blocklist", admin(apiCfg.handlerAddBlocklistTerm))
+	adminMux.Handle("DELETE /admin/blocklist/{word}", admin(apiCfg.handlerDeleteBlocklistTerm))
+	adminMux.Handle("GET /admin/blocklist/changes", admin(apiCfg.handlerGetBlocklistChanges))
+	adminMux.HandleFunc("GET /admin/reports", apiCfg.handlerGetModerationQueue)
+	adminMux.HandleFunc("POST /admin/reports/{reportID}/resolve", apiCfg.handlerResolveReport)
+	adminMux.HandleFunc("POST /admin/users/{id}/suspensions", apiCfg.handlerSuspendUser)
+	adminMux.HandleFunc("GET /admin/users/{id}/suspensions", apiCfg.handlerGetUserSuspensions)
+	adminMux.HandleFunc("DELETE /admin/users/{id}/suspensions", apiCfg.handlerLiftSuspension)
+	adminMux.HandleFunc("PUT /admin/users/{id}/shadow_ban", apiCfg.handlerShadowBanUser)
+	adminMux.HandleFunc("DELETE /admin/users/{id}/shadow_ban", apiCfg.handlerLiftShadowBan)
+	servemux.Handle("/admin/", apiCfg.middlewareRequireRole(auth.RoleModerator, adminMux))
 	servemux.HandleFunc("POST /api/users", apiCfg.handlerCreateUser)
 	servemux.HandleFunc("POST /api/login", apiCfg.handlerLoginUser)
 	servemux.HandleFunc("POST /api/chirps", apiCfg.handlerCreateChirp)
+	servemux.HandleFunc("POST /api/media", apiCfg.handlerUploadMedia)
 	servemux.HandleFunc("GET /api/chirps", apiCfg.handlerGetChirps)
+	servemux.HandleFunc("POST /api/drafts", apiCfg.handlerCreateDraft)
+	servemux.HandleFunc("GET /api/drafts", apiCfg.handlerGetDrafts)
+	servemux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handlerUpdateDraft)
+	servemux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handlerDeleteDraft)
+	servemux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.handlerPublishDraft)
+	servemux.HandleFunc("GET /api/chirps/search", apiCfg.handlerSearchChirps)
+	servemux.HandleFunc("GET /api/stream/chirps", apiCfg.handlerStreamChirps)
+	servemux.HandleFunc("GET /api/ws", apiCfg.handlerWebSocket)
 	servemux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerGetChirp)
+	servemux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerUpdateChirp)
+	servemux.HandleFunc("GET /api/chirps/{chirpID}/history", apiCfg.handlerGetChirpHistory)
+	servemux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerGetThread)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/likes", apiCfg.handlerLikeChirp)
+	servemux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", apiCfg.handlerUnlikeChirp)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.handlerVotePoll)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", apiCfg.handlerBookmarkChirp)
+	servemux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", apiCfg.handlerUnbookmarkChirp)
+	servemux.HandleFunc("GET /api/bookmarks", apiCfg.handlerGetBookmarks)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/pin", apiCfg.handlerPinChirp)
+	servemux.HandleFunc("DELETE /api/chirps/{chirpID}/pin", apiCfg.handlerUnpinChirp)
+	servemux.HandleFunc("PUT /api/pins", apiCfg.handlerReorderPins)
+	servemux.HandleFunc("GET /api/users/{id}/likes", apiCfg.handlerGetUserLikes)
+	servemux.HandleFunc("POST /api/users/{id}/follow", apiCfg.handlerFollowUser)
+	servemux.HandleFunc("DELETE /api/users/{id}/follow", apiCfg.handlerUnfollowUser)
+	servemux.HandleFunc("GET /api/users/{id}/followers", apiCfg.handlerGetFollowers)
+	servemux.HandleFunc("GET /api/users/{id}/following", apiCfg.handlerGetFollowing)
+	servemux.HandleFunc("POST /api/users/{id}/block", apiCfg.handlerBlockUser)
+	servemux.HandleFunc("DELETE /api/users/{id}/block", apiCfg.handlerUnblockUser)
+	servemux.HandleFunc("POST /api/users/{id}/mute", apiCfg.handlerMuteUser)
+	servemux.HandleFunc("DELETE /api/users/{id}/mute", apiCfg.handlerUnmuteUser)
+	servemux.HandleFunc("GET /api/blocks", apiCfg.handlerGetBlocks)
+	servemux.HandleFunc("GET /api/mutes", apiCfg.handlerGetMutes)
+	servemux.HandleFunc("GET /api/timeline", apiCfg.handlerGetTimeline)
+	servemux.HandleFunc("POST /api/reports", apiCfg.handlerCreateReport)
+	servemux.HandleFunc("GET /api/reports", apiCfg.handlerGetReports)
+	servemux.HandleFunc("GET /api/notifications", apiCfg.handlerGetNotifications)
+	servemux.HandleFunc("POST /api/notifications/read", apiCfg.handlerMarkNotificationsRead)
+	servemux.HandleFunc("POST /api/conversations", apiCfg.handlerCreateConversation)
+	servemux.HandleFunc("GET /api/conversations", apiCfg.handlerGetConversations)
+	servemux.HandleFunc("POST /api/conversations/{id}/messages", apiCfg.handlerCreateMessage)
+	servemux.HandleFunc("GET /api/conversations/{id}/messages", apiCfg.handlerGetMessages)
+	servemux.HandleFunc("POST /api/conversations/{id}/read", apiCfg.handlerMarkConversationRead)
 	servemux.HandleFunc("POST /api/refresh", apiCfg.handlerRefreshJWT)
 	servemux.HandleFunc("POST /api/revoke", apiCfg.handlerRevokeRefreshToken)
 	servemux.HandleFunc("PUT /api/users", apiCfg.handlerUpdateUser)
+	servemux.HandleFunc("GET /api/users/{handle}/mentions", apiCfg.handlerGetMentions)
 	servemux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.handlerRestoreChirp)
 	servemux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerUpgradeUser)
+	servemux.HandleFunc("GET /api/hashtags/trending", apiCfg.handlerGetTrendingHashtags)
+	servemux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handlerGetHashtagChirps)
+
+	go apiCfg.purgeDeletedChirps(time.Hour)
+	go apiCfg.publishScheduledChirps(15 * time.Second)
+	go apiCfg.reloadProfanityFilter(10 * time.Minute)
 
 	server := &http.Server{
 		Addr:    ":" + port,
-		Handler: servemux,
+		Handler: apiCfg.middlewareBlockSuspended(servemux),
 	}
 
 	server.ListenAndServe()
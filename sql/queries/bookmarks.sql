@@ -0,0 +1,48 @@
+-- name: BookmarkChirp :execrows
+INSERT INTO bookmarks (user_id, chirp_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: UnbookmarkChirp :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: ListBookmarkedChirpIDs :many
+SELECT chirp_id FROM bookmarks
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: ListBookmarksBefore :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id <> sqlc.arg('user_id')
+)
+AND (sqlc.narg('before_bookmarked_at')::timestamp IS NULL
+    OR (bookmarks.created_at, chirps.id) < (sqlc.narg('before_bookmarked_at'), sqlc.narg('before_id')::uuid))
+ORDER BY bookmarks.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListBookmarksAfter :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id <> sqlc.arg('user_id')
+)
+AND (bookmarks.created_at, chirps.id) > (sqlc.arg('after_bookmarked_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY bookmarks.created_at, chirps.id
+LIMIT sqlc.arg('row_limit');
\ No newline at end of file
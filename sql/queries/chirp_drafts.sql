@@ -0,0 +1,59 @@
+-- name: CreateDraft :one
+INSERT INTO chirp_drafts (id, created_at, updated_at, user_id, body, in_reply_to, media_ids, publish_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: ListDrafts :many
+SELECT * FROM chirp_drafts
+WHERE user_id = $1
+ORDER BY publish_at NULLS LAST, updated_at DESC;
+
+-- name: GetDraftForUpdate :one
+SELECT * FROM chirp_drafts
+WHERE id = $1 AND user_id = $2
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateDraft :one
+UPDATE chirp_drafts
+    SET body = $3,
+    in_reply_to = $4,
+    media_ids = $5,
+    publish_at = $6,
+    failure = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteDraft :exec
+DELETE FROM chirp_drafts
+WHERE id = $1;
+
+-- name: ClaimDueDraft :one
+SELECT * FROM chirp_drafts
+WHERE publish_at <= NOW()
+AND NOT EXISTS (
+    SELECT 1 FROM suspensions
+    WHERE suspensions.user_id = chirp_drafts.user_id
+    AND suspensions.lifted_at IS NULL
+    AND suspensions.expires_at > NOW()
+)
+ORDER BY publish_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: FailDraft :exec
+UPDATE chirp_drafts
+    SET publish_at = NULL,
+    failure = $2,
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
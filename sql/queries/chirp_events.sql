@@ -0,0 +1,22 @@
+-- name: CreateChirpEvent :one
+INSERT INTO chirp_events (created_at, kind, chirp_id, user_id, data)
+SELECT NOW(), sqlc.arg('kind')::text, sqlc.arg('chirp_id')::uuid, sqlc.arg('user_id')::uuid, sqlc.arg('data')::jsonb
+WHERE NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = sqlc.arg('user_id') AND users.shadow_banned_at IS NOT NULL
+)
+RETURNING *;
+
+-- name: Notify :exec
+SELECT pg_notify(sqlc.arg('channel')::text, sqlc.arg('payload')::text);
+
+-- name: ListChirpEventsAfter :many
+SELECT * FROM chirp_events
+WHERE id > sqlc.arg('after_id')
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+ORDER BY id
+LIMIT sqlc.arg('row_limit');
+
+-- name: PurgeChirpEvents :execrows
+DELETE FROM chirp_events
+WHERE created_at < sqlc.arg('created_before')::timestamp;
\ No newline at end of file
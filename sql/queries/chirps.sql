@@ -1,27 +1,250 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, thread_id, kind, original_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    (SELECT COALESCE(parent.thread_id, parent.id) FROM chirps AS parent WHERE parent.id = $3),
+    $4,
+    $5
 )
 RETURNING *;
 
 -- name: ListChirps :many
 SELECT * FROM chirps
+WHERE deleted_at IS NULL
 ORDER BY created_at;
 
 -- name: GetChirpsByAuthor :many
 SELECT * FROM chirps
 WHERE user_id = $1
+AND deleted_at IS NULL
 ORDER BY created_at;
 
 -- name: GetChirp :one
 SELECT * FROM chirps
-WHERE id = $1 LIMIT 1;
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1;
 
--- name: DeleteChirp :exec
+-- name: GetVisibleChirp :one
+SELECT * FROM chirps
+WHERE id = sqlc.arg('id')
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+LIMIT 1;
+
+-- name: GetDeletedChirp :one
+SELECT * FROM chirps
+WHERE id = $1
+AND deleted_at IS NOT NULL
+LIMIT 1;
+
+-- name: GetChirpForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+    SET body = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+);
+
+-- name: SoftDeleteChirp :exec
+UPDATE chirps
+    SET deleted_at = NOW()
+WHERE id = $1;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+    SET deleted_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: PurgeDeletedChirps :execrows
 DELETE FROM chirps
-WHERE id = $1;
\ No newline at end of file
+WHERE deleted_at < sqlc.arg('deleted_before')::timestamp
+AND hidden_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM chirps AS replies
+    WHERE replies.thread_id = chirps.id
+)
+-- open reports keep their chirp, so they can't collide once it's set to NULL
+AND NOT EXISTS (
+    SELECT 1 FROM reports
+    WHERE reports.chirp_id = chirps.id
+    AND reports.status = 'open'
+);
+
+-- name: HideChirp :one
+UPDATE chirps
+    SET deleted_at = COALESCE(deleted_at, NOW()),
+    hidden_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListChirpsAfter :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since'))
+AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until'))
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('after_created_at'), sqlc.narg('after_id')::uuid))
+ORDER BY created_at, id
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListChirpsBefore :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since'))
+AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until'))
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: SearchChirpsAfter :many
+SELECT sqlc.embed(chirps), ts_rank(chirps.body_tsv, websearch_to_tsquery('english', sqlc.arg('query'))) AS rank
+FROM chirps
+WHERE chirps.body_tsv @@ websearch_to_tsquery('english', sqlc.arg('query'))
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id'))
+AND (sqlc.narg('after_rank')::real IS NULL
+    OR (ts_rank(chirps.body_tsv, websearch_to_tsquery('english', sqlc.arg('query'))), chirps.created_at, chirps.id)
+        < (sqlc.narg('after_rank'), sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: SearchChirpsBefore :many
+SELECT sqlc.embed(chirps), ts_rank(chirps.body_tsv, websearch_to_tsquery('english', sqlc.arg('query'))) AS rank
+FROM chirps
+WHERE chirps.body_tsv @@ websearch_to_tsquery('english', sqlc.arg('query'))
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id'))
+AND (ts_rank(chirps.body_tsv, websearch_to_tsquery('english', sqlc.arg('query'))), chirps.created_at, chirps.id)
+    > (sqlc.arg('before_rank')::real, sqlc.arg('before_created_at')::timestamp, sqlc.arg('before_id')::uuid)
+ORDER BY rank, chirps.created_at, chirps.id
+LIMIT sqlc.arg('row_limit');
+
+-- name: CountReplies :many
+SELECT in_reply_to, COUNT(*) AS replies FROM chirps
+WHERE in_reply_to = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+GROUP BY in_reply_to;
+
+-- name: ListThread :many
+SELECT * FROM chirps
+WHERE (id = sqlc.arg('root_id') OR thread_id = sqlc.arg('root_id'))
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+-- like a single chirp, the root shows up even when its author is muted
+AND (id = sqlc.arg('root_id') OR NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+))
+ORDER BY created_at, id;
\ No newline at end of file
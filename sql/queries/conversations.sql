@@ -0,0 +1,72 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (id, created_at, updated_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: AddConversationParticipants :exec
+INSERT INTO conversation_participants (conversation_id, user_id, created_at)
+SELECT sqlc.arg('conversation_id')::uuid, participant_id, NOW()
+FROM unnest(sqlc.arg('user_ids')::uuid[]) AS participant_id
+ON CONFLICT DO NOTHING;
+
+-- name: LockDirectConversation :exec
+SELECT pg_advisory_xact_lock(hashtextextended(
+    LEAST(sqlc.arg('user_id')::uuid, sqlc.arg('other_id')::uuid)::text
+    || GREATEST(sqlc.arg('user_id')::uuid, sqlc.arg('other_id')::uuid)::text,
+    0
+));
+
+-- name: FindDirectConversation :one
+SELECT conversations.* FROM conversations
+JOIN conversation_participants AS mine
+    ON mine.conversation_id = conversations.id AND mine.user_id = sqlc.arg('user_id')
+JOIN conversation_participants AS theirs
+    ON theirs.conversation_id = conversations.id AND theirs.user_id = sqlc.arg('other_id')
+WHERE (SELECT COUNT(*) FROM conversation_participants WHERE conversation_id = conversations.id) = 2
+LIMIT 1;
+
+-- name: GetConversationForUser :one
+SELECT conversations.* FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversations.id = sqlc.arg('id')
+AND conversation_participants.user_id = sqlc.arg('user_id')
+LIMIT 1;
+
+-- name: ListConversations :many
+SELECT sqlc.embed(conversations), conversation_participants.last_read_at,
+    (SELECT COUNT(*) FROM messages
+    WHERE messages.conversation_id = conversations.id
+    AND messages.sender_id <> sqlc.arg('user_id')
+    AND (conversation_participants.last_read_at IS NULL
+        OR messages.created_at > conversation_participants.last_read_at)) AS unread
+FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversation_participants.user_id = sqlc.arg('user_id')
+ORDER BY conversations.updated_at DESC, conversations.id DESC;
+
+-- name: ListConversationParticipants :many
+SELECT conversation_id, user_id FROM conversation_participants
+WHERE conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+ORDER BY created_at, user_id;
+
+-- name: TouchConversation :exec
+UPDATE conversations
+    SET updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkConversationRead :exec
+UPDATE conversation_participants
+    SET last_read_at = NOW()
+WHERE conversation_id = $1 AND user_id = $2;
+
+-- name: IsBlockedInConversation :one
+SELECT EXISTS (
+    SELECT 1 FROM conversation_participants
+    JOIN blocks ON (blocks.blocker_id = conversation_participants.user_id AND blocks.blocked_id = sqlc.arg('user_id'))
+        OR (blocks.blocker_id = sqlc.arg('user_id') AND blocks.blocked_id = conversation_participants.user_id)
+    WHERE conversation_participants.conversation_id = sqlc.arg('conversation_id')
+) AS blocked;
\ No newline at end of file
@@ -0,0 +1,99 @@
+-- name: FollowUser :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg('user_id') AND followee_id = sqlc.arg('other_id'))
+OR (follower_id = sqlc.arg('other_id') AND followee_id = sqlc.arg('user_id'));
+
+-- name: CountFollows :one
+SELECT
+    (SELECT COUNT(*) FROM follows WHERE followee_id = sqlc.arg('user_id')) AS followers,
+    (SELECT COUNT(*) FROM follows WHERE follower_id = sqlc.arg('user_id')) AS following;
+
+-- name: ListFollowersBefore :many
+SELECT users.id, users.handle, follows.created_at AS followed_at FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg('user_id')
+AND (sqlc.narg('before_followed_at')::timestamp IS NULL
+    OR (follows.created_at, users.id) < (sqlc.narg('before_followed_at'), sqlc.narg('before_id')::uuid))
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListFollowersAfter :many
+SELECT users.id, users.handle, follows.created_at AS followed_at FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg('user_id')
+AND (follows.created_at, users.id) > (sqlc.arg('after_followed_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY follows.created_at, users.id
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListFollowingBefore :many
+SELECT users.id, users.handle, follows.created_at AS followed_at FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+AND (sqlc.narg('before_followed_at')::timestamp IS NULL
+    OR (follows.created_at, users.id) < (sqlc.narg('before_followed_at'), sqlc.narg('before_id')::uuid))
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListFollowingAfter :many
+SELECT users.id, users.handle, follows.created_at AS followed_at FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+AND (follows.created_at, users.id) > (sqlc.arg('after_followed_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY follows.created_at, users.id
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListFollowingIDs :many
+SELECT followee_id FROM follows
+WHERE follower_id = $1;
+
+-- name: ListTimelineBefore :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id <> sqlc.arg('user_id')
+)
+AND (user_id = sqlc.arg('user_id')
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg('user_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListTimelineAfter :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id <> sqlc.arg('user_id')
+)
+AND (user_id = sqlc.arg('user_id')
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg('user_id') AND mutes.muted_id = chirps.user_id
+)
+AND (created_at, id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg('row_limit');
\ No newline at end of file
@@ -0,0 +1,92 @@
+-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, created_at, tag)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1
+)
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING *;
+
+-- name: AddChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteStaleChirpHashtags :exec
+DELETE FROM chirp_hashtags
+USING hashtags
+WHERE hashtags.id = chirp_hashtags.hashtag_id
+AND chirp_hashtags.chirp_id = sqlc.arg('chirp_id')
+AND NOT hashtags.tag = ANY(sqlc.arg('tags')::text[]);
+
+-- name: ListHashtagChirpsBefore :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirps.id = chirp_hashtags.chirp_id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg('tag')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListHashtagChirpsAfter :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirps.id = chirp_hashtags.chirp_id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg('tag')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (chirps.created_at, chirps.id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY chirps.created_at, chirps.id
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListTrendingHashtags :many
+SELECT hashtags.tag, COUNT(*) AS uses
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirp_hashtags.created_at >= sqlc.arg('since')::timestamp
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+)
+GROUP BY hashtags.tag
+ORDER BY uses DESC, hashtags.tag
+LIMIT sqlc.arg('row_limit');
\ No newline at end of file
@@ -0,0 +1,68 @@
+-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, mime_type, size_bytes, width, height, storage_key)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetMedia :one
+SELECT * FROM media
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetVisibleMedia :one
+SELECT media.* FROM media
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+LEFT JOIN chirps ON chirps.id = chirp_media.chirp_id
+WHERE media.id = sqlc.arg('id')
+AND (
+    -- uploads that aren't attached yet are only there for their uploader
+    (chirp_media.chirp_id IS NULL AND media.user_id = sqlc.narg('viewer_id'))
+    OR (
+        chirp_media.chirp_id IS NOT NULL
+        AND chirps.deleted_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id
+            AND users.shadow_banned_at IS NOT NULL
+            AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+            OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+        )
+    )
+)
+LIMIT 1;
+
+-- name: PurgeUnattachedMedia :many
+DELETE FROM media
+WHERE created_at < sqlc.arg('created_before')::timestamp
+AND NOT EXISTS (SELECT 1 FROM chirp_media WHERE chirp_media.media_id = media.id)
+AND NOT EXISTS (SELECT 1 FROM chirp_drafts WHERE media.id = ANY(chirp_drafts.media_ids))
+RETURNING storage_key;
+
+-- name: CountAttachableMedia :one
+SELECT COUNT(*) FROM media
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+AND user_id = sqlc.arg('user_id')
+AND NOT EXISTS (SELECT 1 FROM chirp_media WHERE chirp_media.media_id = media.id);
+
+-- name: AttachChirpMedia :exec
+INSERT INTO chirp_media (chirp_id, media_id, position)
+SELECT sqlc.arg('chirp_id')::uuid, attached.media_id, attached.position
+FROM unnest(sqlc.arg('media_ids')::uuid[]) WITH ORDINALITY AS attached(media_id, position);
+
+-- name: ListChirpMedia :many
+SELECT chirp_media.chirp_id, sqlc.embed(media) FROM chirp_media
+JOIN media ON media.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_media.chirp_id, chirp_media.position;
\ No newline at end of file
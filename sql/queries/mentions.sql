@@ -0,0 +1,68 @@
+-- name: AddChirpMentions :many
+INSERT INTO chirp_mentions (chirp_id, user_id, created_at)
+SELECT sqlc.arg('chirp_id')::uuid, users.id, NOW()
+FROM users
+WHERE users.handle = ANY(sqlc.arg('handles')::text[])
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    JOIN chirps ON chirps.id = sqlc.arg('chirp_id')
+    WHERE blocks.blocker_id = users.id AND blocks.blocked_id = chirps.user_id
+)
+ON CONFLICT DO NOTHING
+RETURNING user_id;
+
+-- name: DeleteStaleChirpMentions :exec
+DELETE FROM chirp_mentions
+USING users
+WHERE users.id = chirp_mentions.user_id
+AND chirp_mentions.chirp_id = sqlc.arg('chirp_id')
+AND (users.handle IS NULL OR NOT users.handle = ANY(sqlc.arg('handles')::text[]));
+
+-- name: ListMentionChirpsBefore :many
+SELECT chirps.* FROM chirps
+JOIN chirp_mentions ON chirps.id = chirp_mentions.chirp_id
+WHERE chirp_mentions.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListMentionChirpsAfter :many
+SELECT chirps.* FROM chirps
+JOIN chirp_mentions ON chirps.id = chirp_mentions.chirp_id
+WHERE chirp_mentions.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (chirps.created_at, chirps.id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY chirps.created_at, chirps.id
+LIMIT sqlc.arg('row_limit');
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- name: CreateMessage :one
+INSERT INTO messages (id, created_at, conversation_id, sender_id, body)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: ListMessagesBefore :many
+SELECT * FROM messages
+WHERE conversation_id = sqlc.arg('conversation_id')
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListMessagesAfter :many
+SELECT * FROM messages
+WHERE conversation_id = sqlc.arg('conversation_id')
+AND (created_at, id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg('row_limit');
\ No newline at end of file
@@ -0,0 +1,87 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (id, created_at, updated_at, user_id, kind, chirp_id, actor_ids)
+SELECT gen_random_uuid(), NOW(), NOW(), sqlc.arg('user_id')::uuid, sqlc.arg('kind')::text, sqlc.narg('chirp_id')::uuid, ARRAY[sqlc.arg('actor_id')::uuid]
+WHERE sqlc.arg('user_id') <> sqlc.arg('actor_id')
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg('user_id') AND mutes.muted_id = sqlc.arg('actor_id')
+)
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = sqlc.arg('actor_id') AND users.shadow_banned_at IS NOT NULL
+)
+ON CONFLICT (user_id, kind, COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid), COALESCE(report_id, '00000000-0000-0000-0000-000000000000'::uuid)) WHERE read_at IS NULL
+DO UPDATE SET
+    updated_at = NOW(),
+    actor_ids = CASE
+        WHEN sqlc.arg('actor_id') = ANY(notifications.actor_ids) THEN notifications.actor_ids
+        ELSE array_append(notifications.actor_ids, sqlc.arg('actor_id'))
+    END
+RETURNING *;
+
+-- name: NotifyReport :one
+INSERT INTO notifications (id, created_at, updated_at, user_id, kind, report_id, actor_ids)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    'report',
+    $2,
+    '{}'
+)
+RETURNING *;
+
+-- name: NotifyMentions :many
+INSERT INTO notifications (id, created_at, updated_at, user_id, kind, chirp_id, actor_ids)
+SELECT gen_random_uuid(), NOW(), NOW(), chirp_mentions.user_id, 'mention', chirps.id, ARRAY[chirps.user_id]
+FROM chirp_mentions
+JOIN chirps ON chirps.id = chirp_mentions.chirp_id
+WHERE chirp_mentions.chirp_id = sqlc.arg('chirp_id')
+AND chirp_mentions.user_id = ANY(sqlc.arg('user_ids')::uuid[])
+AND chirp_mentions.user_id <> chirps.user_id
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = chirp_mentions.user_id AND mutes.muted_id = chirps.user_id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.shadow_banned_at IS NOT NULL
+)
+ON CONFLICT (user_id, kind, COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid), COALESCE(report_id, '00000000-0000-0000-0000-000000000000'::uuid)) WHERE read_at IS NULL
+DO NOTHING
+RETURNING *;
+
+-- name: ListNotificationsBefore :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg('user_id')
+AND (NOT sqlc.arg('unread_only')::boolean OR read_at IS NULL)
+AND (sqlc.narg('before_updated_at')::timestamp IS NULL
+    OR (updated_at, id) < (sqlc.narg('before_updated_at'), sqlc.narg('before_id')::uuid))
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListNotificationsAfter :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg('user_id')
+AND (NOT sqlc.arg('unread_only')::boolean OR read_at IS NULL)
+AND (updated_at, id) > (sqlc.arg('after_updated_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY updated_at, id
+LIMIT sqlc.arg('row_limit');
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationsRead :execrows
+UPDATE notifications
+    SET read_at = NOW()
+WHERE user_id = sqlc.arg('user_id')
+AND id = ANY(sqlc.arg('ids')::uuid[])
+AND read_at IS NULL;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+    SET read_at = NOW()
+WHERE user_id = $1
+AND read_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,43 @@
+-- name: LockUser :exec
+SELECT id FROM users
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CountPins :one
+SELECT COUNT(*) FROM pins
+WHERE user_id = $1;
+
+-- name: PinChirp :execrows
+INSERT INTO pins (chirp_id, user_id, position, created_at)
+SELECT sqlc.arg('chirp_id'), sqlc.arg('user_id'), COALESCE(MAX(position), 0) + 1, NOW() FROM pins
+WHERE user_id = sqlc.arg('user_id')
+ON CONFLICT (chirp_id) DO NOTHING;
+
+-- name: UnpinChirp :execrows
+DELETE FROM pins
+WHERE chirp_id = $1 AND user_id = $2;
+
+-- name: ListPinIDs :many
+SELECT chirp_id FROM pins
+WHERE user_id = $1
+ORDER BY position, created_at;
+
+-- name: ReorderPins :exec
+UPDATE pins
+    SET position = ordered.position
+FROM unnest(sqlc.arg('chirp_ids')::uuid[]) WITH ORDINALITY AS ordered(chirp_id, position)
+WHERE pins.chirp_id = ordered.chirp_id
+AND pins.user_id = sqlc.arg('user_id');
+
+-- name: ListPinnedChirps :many
+SELECT chirps.* FROM pins
+JOIN chirps ON chirps.id = pins.chirp_id
+WHERE pins.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id
+    AND users.shadow_banned_at IS NOT NULL
+    AND users.id IS DISTINCT FROM sqlc.narg('viewer_id')
+)
+ORDER BY pins.position, pins.created_at;
\ No newline at end of file
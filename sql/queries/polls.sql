@@ -0,0 +1,36 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, closes_at)
+VALUES (
+    $1,
+    NOW(),
+    $2
+);
+
+-- name: AddPollOptions :exec
+INSERT INTO poll_options (chirp_id, position, text)
+SELECT sqlc.arg('chirp_id')::uuid, options.position, options.text
+FROM unnest(sqlc.arg('texts')::text[]) WITH ORDINALITY AS options(text, position);
+
+-- name: GetPoll :one
+SELECT polls.closes_at, COUNT(poll_options.position) AS options FROM polls
+JOIN poll_options ON poll_options.chirp_id = polls.chirp_id
+WHERE polls.chirp_id = $1
+GROUP BY polls.chirp_id;
+
+-- name: VoteInPoll :execrows
+INSERT INTO poll_votes (chirp_id, user_id, position, created_at)
+SELECT polls.chirp_id, sqlc.arg('user_id'), sqlc.arg('position'), NOW() FROM polls
+WHERE polls.chirp_id = sqlc.arg('chirp_id') AND polls.closes_at > NOW()
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: ListPollOptions :many
+SELECT polls.chirp_id, polls.closes_at, poll_options.position, poll_options.text, COUNT(poll_votes.user_id) AS votes FROM polls
+JOIN poll_options ON poll_options.chirp_id = polls.chirp_id
+LEFT JOIN poll_votes ON poll_votes.chirp_id = poll_options.chirp_id AND poll_votes.position = poll_options.position
+WHERE polls.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY polls.chirp_id, polls.closes_at, poll_options.position, poll_options.text
+ORDER BY polls.chirp_id, poll_options.position;
+
+-- name: ListPollVotes :many
+SELECT chirp_id, position FROM poll_votes
+WHERE user_id = sqlc.arg('user_id') AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
\ No newline at end of file
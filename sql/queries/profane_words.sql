@@ -0,0 +1,53 @@
+-- name: ListProfaneWords :many
+SELECT * FROM profane_words
+ORDER BY word;
+
+-- name: AddProfaneWord :execrows
+INSERT INTO profane_words (word, action, created_at, added_by)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    $3
+)
+ON CONFLICT (word) DO NOTHING;
+
+-- name: UpdateProfaneWord :execrows
+UPDATE profane_words
+    SET action = $2
+WHERE word = $1 AND action <> $2;
+
+-- name: GetProfaneWord :one
+SELECT * FROM profane_words
+WHERE word = $1
+LIMIT 1;
+
+-- name: DeleteProfaneWord :execrows
+DELETE FROM profane_words
+WHERE word = $1;
+
+-- name: CreateProfaneWordChange :exec
+INSERT INTO profane_word_changes (id, created_at, word, change, action, user_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: ListProfaneWordChangesBefore :many
+SELECT * FROM profane_word_changes
+WHERE (sqlc.narg('word')::text IS NULL OR word = sqlc.narg('word'))
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListProfaneWordChangesAfter :many
+SELECT * FROM profane_word_changes
+WHERE (sqlc.narg('word')::text IS NULL OR word = sqlc.narg('word'))
+AND (created_at, id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg('row_limit');
\ No newline at end of file
@@ -0,0 +1,106 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, reporter_id, user_id, chirp_id, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: FlagChirp :exec
+INSERT INTO reports (id, created_at, updated_at, user_id, chirp_id, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    'flagged',
+    $3
+)
+ON CONFLICT (COALESCE(reporter_id, '00000000-0000-0000-0000-000000000000'::uuid), user_id, COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid)) WHERE status = 'open'
+DO NOTHING;
+
+-- name: GetReportForUpdate :one
+SELECT * FROM reports
+WHERE id = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListReportsBefore :many
+SELECT sqlc.embed(reports), moderation_actions.action, moderation_actions.moderator_id, moderation_actions.note FROM reports
+LEFT JOIN moderation_actions ON moderation_actions.id = reports.action_id
+WHERE reports.status = sqlc.arg('status')
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (reports.created_at, reports.id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY reports.created_at DESC, reports.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListReportsAfter :many
+SELECT sqlc.embed(reports), moderation_actions.action, moderation_actions.moderator_id, moderation_actions.note FROM reports
+LEFT JOIN moderation_actions ON moderation_actions.id = reports.action_id
+WHERE reports.status = sqlc.arg('status')
+AND (reports.created_at, reports.id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY reports.created_at, reports.id
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListUserReportsBefore :many
+SELECT * FROM reports
+WHERE reporter_id = sqlc.arg('reporter_id')
+AND (sqlc.narg('before_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('before_created_at'), sqlc.narg('before_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListUserReportsAfter :many
+SELECT * FROM reports
+WHERE reporter_id = sqlc.arg('reporter_id')
+AND (created_at, id) > (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg('row_limit');
+
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, created_at, moderator_id, action, chirp_id, user_id, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: ResolveReports :many
+UPDATE reports
+    SET status = sqlc.arg('status'),
+    action_id = sqlc.arg('action_id'),
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+AND status = 'open'
+RETURNING *;
+
+-- name: ListOpenReportIDs :many
+SELECT id FROM reports
+WHERE status = 'open'
+AND user_id = sqlc.arg('user_id')
+AND (sqlc.narg('chirp_id')::uuid IS NULL OR chirp_id = sqlc.narg('chirp_id'))
+FOR UPDATE;
+
+-- name: CreateSuspension :one
+INSERT INTO suspensions (id, created_at, user_id, moderator_id, reason, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- name: GetActiveSuspension :one
+SELECT * FROM suspensions
+WHERE user_id = $1
+AND lifted_at IS NULL
+AND expires_at > NOW()
+ORDER BY expires_at DESC
+LIMIT 1;
+
+-- name: ListUserSuspensions :many
+SELECT * FROM suspensions
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: LiftSuspensions :many
+UPDATE suspensions
+    SET lifted_at = NOW()
+WHERE user_id = $1
+AND lifted_at IS NULL
+AND expires_at > NOW()
+RETURNING *;
+
+-- name: ShadowBanUser :one
+UPDATE users
+    SET shadow_banned_at = COALESCE(shadow_banned_at, NOW()),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: LiftShadowBan :one
+UPDATE users
+    SET shadow_banned_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
@@ -1,32 +1,59 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
     $2,
-    false
+    false,
+    $3
 )
-RETURNING id, created_at, updated_at, email, is_chirpy_red;
+RETURNING id, created_at, updated_at, email, is_chirpy_red, handle;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
 WHERE email = $1 LIMIT 1;
 
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: GetUserByHandle :one
+SELECT * FROM users
+WHERE handle = $1 LIMIT 1;
+
 -- name: DeleteUsers :exec
 DELETE FROM users;
 
 -- name: UpdateUser :one
 UPDATE users
-    SET email = $1,
-    hashed_password = $2,
+    SET email = sqlc.arg('email'),
+    hashed_password = sqlc.arg('hashed_password'),
+    handle = COALESCE(sqlc.narg('handle'), handle),
     updated_at = NOW()
-WHERE id = $3
-RETURNING id, created_at, updated_at, email, is_chirpy_red;
+WHERE id = sqlc.arg('id')
+RETURNING id, created_at, updated_at, email, is_chirpy_red, handle;
 
 -- name: UpgradeUser :exec
 UPDATE users
     SET is_chirpy_red = true,
     updated_at = NOW()
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+    SET role = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin';
+
+-- name: ListAdminIDsForUpdate :many
+SELECT id FROM users
+WHERE role = 'admin'
+ORDER BY id
+FOR UPDATE;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD body_tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', body)) STORED;
+
+CREATE INDEX chirps_body_tsv_idx ON chirps USING GIN (body_tsv);
+
+-- +goose Down
+DROP INDEX chirps_body_tsv_idx;
+ALTER TABLE chirps DROP COLUMN body_tsv;
\ No newline at end of file
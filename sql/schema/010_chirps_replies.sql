@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD in_reply_to UUID,
+ADD thread_id UUID,
+ADD CONSTRAINT fk_in_reply_to
+    FOREIGN KEY (in_reply_to)
+    REFERENCES chirps (id)
+    ON DELETE SET NULL;
+
+CREATE INDEX chirps_in_reply_to_idx ON chirps (in_reply_to);
+CREATE INDEX chirps_thread_id_created_at_idx ON chirps (thread_id, created_at);
+
+-- +goose Down
+DROP INDEX chirps_thread_id_created_at_idx;
+DROP INDEX chirps_in_reply_to_idx;
+ALTER TABLE chirps
+DROP CONSTRAINT fk_in_reply_to,
+DROP COLUMN thread_id,
+DROP COLUMN in_reply_to;
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD kind TEXT NOT NULL DEFAULT 'chirp',
+ADD original_id UUID,
+ADD CONSTRAINT chirps_kind_check
+    CHECK (kind IN ('chirp', 'rechirp', 'quote')),
+ADD CONSTRAINT fk_original_id
+    FOREIGN KEY (original_id)
+    REFERENCES chirps (id)
+    ON DELETE SET NULL;
+
+-- a user can only rechirp the same chirp once
+CREATE UNIQUE INDEX chirps_user_id_original_id_rechirp_key ON chirps (user_id, original_id) WHERE kind = 'rechirp';
+CREATE INDEX chirps_original_id_idx ON chirps (original_id);
+
+-- +goose Down
+DROP INDEX chirps_original_id_idx;
+DROP INDEX chirps_user_id_original_id_rechirp_key;
+ALTER TABLE chirps
+DROP CONSTRAINT fk_original_id,
+DROP CONSTRAINT chirps_kind_check,
+DROP COLUMN original_id,
+DROP COLUMN kind;
\ No newline at end of file
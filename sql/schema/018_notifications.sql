@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    kind TEXT NOT NULL,
+    chirp_id UUID,
+    actor_ids UUID[] NOT NULL,
+    read_at TIMESTAMP,
+    CONSTRAINT notifications_kind_check
+        CHECK (kind IN ('reply', 'mention', 'like', 'follow')),
+    CONSTRAINT fk_user_id
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chirp_id
+        FOREIGN KEY (chirp_id)
+        REFERENCES chirps (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX notifications_user_id_updated_at_id_idx ON notifications (user_id, updated_at, id);
+
+-- repeated events pile onto the same unread notification, follows have no
+-- chirp and are grouped together
+CREATE UNIQUE INDEX notifications_unread_group_key
+    ON notifications (user_id, kind, COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid))
+    WHERE read_at IS NULL;
+
+-- +goose Down
+DROP TABLE notifications;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+-- no foreign key on chirp_id, deletion events outlive the chirps they're about
+CREATE TABLE chirp_events (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    kind TEXT NOT NULL,
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    CONSTRAINT chirp_events_kind_check
+        CHECK (kind IN ('chirp.created', 'chirp.deleted'))
+);
+
+CREATE INDEX chirp_events_created_at_idx ON chirp_events (created_at);
+
+-- +goose Down
+DROP TABLE chirp_events;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- +goose Up
+-- drafts have no publish_at, scheduled chirps do. Neither is a chirp until
+-- it's published, at which point the draft row goes away.
+CREATE TABLE chirp_drafts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    in_reply_to UUID,
+    media_ids UUID[] NOT NULL,
+    publish_at TIMESTAMP,
+    failure TEXT,
+    CONSTRAINT fk_user_id
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_drafts_user_id_idx ON chirp_drafts (user_id);
+CREATE INDEX chirp_drafts_publish_at_idx ON chirp_drafts (publish_at) WHERE publish_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE chirp_drafts;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+-- words are stored normalized, see moderation.Normalize
+CREATE TABLE profane_words (
+    word TEXT PRIMARY KEY,
+    action TEXT NOT NULL DEFAULT 'mask',
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT profane_words_action_check CHECK (action IN ('mask', 'flag', 'reject'))
+);
+
+-- +goose Down
+DROP TABLE profane_words;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE profane_words ADD COLUMN added_by UUID;
+ALTER TABLE profane_words ADD CONSTRAINT fk_added_by
+    FOREIGN KEY (added_by)
+    REFERENCES users (id)
+    ON DELETE SET NULL;
+
+-- every change to the blocklist, kept after the word itself is removed
+CREATE TABLE profane_word_changes (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    word TEXT NOT NULL,
+    change TEXT NOT NULL,
+    action TEXT,
+    user_id UUID,
+    CONSTRAINT profane_word_changes_change_check CHECK (change IN ('added', 'updated', 'removed')),
+    CONSTRAINT fk_user_id
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX profane_word_changes_created_at_idx ON profane_word_changes (created_at, id);
+
+-- +goose Down
+DROP TABLE profane_word_changes;
+ALTER TABLE profane_words DROP COLUMN added_by;
\ No newline at end of file
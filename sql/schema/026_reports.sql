@@ -0,0 +1,126 @@
+-- +goose Up
+-- chirps hidden by moderators stay soft-deleted for good, kept around
+-- instead of being purged
+ALTER TABLE chirps ADD COLUMN hidden_at TIMESTAMP;
+
+CREATE TABLE suspensions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    moderator_id UUID,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    lifted_at TIMESTAMP,
+    CONSTRAINT fk_user_id
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_moderator_id
+        FOREIGN KEY (moderator_id)
+        REFERENCES users (id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX suspensions_user_id_idx ON suspensions (user_id, expires_at);
+
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    moderator_id UUID,
+    action TEXT NOT NULL,
+    chirp_id UUID,
+    user_id UUID,
+    note TEXT NOT NULL,
+    CONSTRAINT moderation_actions_action_check
+        CHECK (action IN ('dismiss', 'hide_chirp', 'suspend_user')),
+    CONSTRAINT fk_moderator_id
+        FOREIGN KEY (moderator_id)
+        REFERENCES users (id)
+        ON DELETE SET NULL,
+    CONSTRAINT fk_chirp_id
+        FOREIGN KEY (chirp_id)
+        REFERENCES chirps (id)
+        ON DELETE SET NULL,
+    CONSTRAINT fk_user_id
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE SET NULL
+);
+
+-- reports without a reporter are filed by the content filter
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    reporter_id UUID,
+    user_id UUID NOT NULL,
+    chirp_id UUID,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    action_id UUID,
+    CONSTRAINT reports_status_check
+        CHECK (status IN ('open', 'dismissed', 'actioned')),
+    CONSTRAINT fk_reporter_id
+        FOREIGN KEY (reporter_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_user_id
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chirp_id
+        FOREIGN KEY (chirp_id)
+        REFERENCES chirps (id)
+        ON DELETE SET NULL,
+    CONSTRAINT fk_action_id
+        FOREIGN KEY (action_id)
+        REFERENCES moderation_actions (id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX reports_status_created_at_id_idx ON reports (status, created_at, id);
+CREATE INDEX reports_reporter_id_created_at_id_idx ON reports (reporter_id, created_at, id);
+
+-- a single open report per reporter and target
+CREATE UNIQUE INDEX reports_open_key
+    ON reports (
+        COALESCE(reporter_id, '00000000-0000-0000-0000-000000000000'::uuid),
+        user_id,
+        COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid)
+    )
+    WHERE status = 'open';
+
+-- reporters hear back about each of their reports separately
+ALTER TABLE notifications ADD COLUMN report_id UUID;
+ALTER TABLE notifications ADD CONSTRAINT fk_report_id
+    FOREIGN KEY (report_id)
+    REFERENCES reports (id)
+    ON DELETE CASCADE;
+ALTER TABLE notifications DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_kind_check
+    CHECK (kind IN ('reply', 'mention', 'like', 'follow', 'report'));
+DROP INDEX notifications_unread_group_key;
+CREATE UNIQUE INDEX notifications_unread_group_key
+    ON notifications (
+        user_id,
+        kind,
+        COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid),
+        COALESCE(report_id, '00000000-0000-0000-0000-000000000000'::uuid)
+    )
+    WHERE read_at IS NULL;
+
+-- +goose Down
+DROP INDEX notifications_unread_group_key;
+DELETE FROM notifications WHERE kind = 'report';
+CREATE UNIQUE INDEX notifications_unread_group_key
+    ON notifications (user_id, kind, COALESCE(chirp_id, '00000000-0000-0000-0000-000000000000'::uuid))
+    WHERE read_at IS NULL;
+ALTER TABLE notifications DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_kind_check
+    CHECK (kind IN ('reply', 'mention', 'like', 'follow'));
+ALTER TABLE notifications DROP COLUMN report_id;
+DROP TABLE reports;
+DROP TABLE moderation_actions;
+DROP TABLE suspensions;
+ALTER TABLE chirps DROP COLUMN hidden_at;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+-- the chirps of shadow-banned users are only shown to themselves
+ALTER TABLE users ADD COLUMN shadow_banned_at TIMESTAMP;
+
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check
+    CHECK (action IN ('dismiss', 'hide_chirp', 'suspend_user', 'lift_suspension', 'shadow_ban', 'lift_shadow_ban'));
+
+-- +goose Down
+DELETE FROM moderation_actions WHERE action IN ('lift_suspension', 'shadow_ban', 'lift_shadow_ban');
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check
+    CHECK (action IN ('dismiss', 'hide_chirp', 'suspend_user'));
+ALTER TABLE users DROP COLUMN shadow_banned_at;
\ No newline at end of file
@@ -0,0 +1,27 @@
+package main
+
+import(
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/neriAle/chirpy/internal/auth"
+)
+
+// viewerFromRequest identifies the caller of a public endpoint. Anonymous
+// requests get a null ID, a token that is present but invalid is an error.
+func (cfg *apiConfig) viewerFromRequest(req *http.Request) (uuid.NullUUID, error) {
+	if req.Header.Get("Authorization") == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: userId, Valid: true}, nil
+}
\ No newline at end of file